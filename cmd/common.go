@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+	"github.com/keenmate/db-gen/private/dbGen"
 	"github.com/keenmate/db-gen/private/helpers"
 )
 
@@ -8,6 +10,7 @@ const (
 	keyDebug            = "debug"
 	keyConnectionString = "connectionString"
 	keyConfig           = "config"
+	keyTarget           = "target"
 )
 
 var commonFlags = []helpers.FlagArgument{
@@ -16,6 +19,10 @@ var commonFlags = []helpers.FlagArgument{
 	helpers.NewStringFlag(keyConnectionString, "c", "", "Path to configuration file"),
 }
 
+var targetFlag = helpers.NewStringSliceFlag(keyTarget, "t", nil, "Names of targets to use, all targets are used if not set")
+
+var variablesFlag = helpers.NewStringArrayFlag(dbGen.VariablesFlagKey, "", nil, "Template variable as key=value, overrides Variables from configuration, can be repeated")
+
 func printDatabaseChanges(databaseChanges string) {
 	if len(databaseChanges) == 0 {
 		helpers.LogBold("No database changes detected")
@@ -24,3 +31,50 @@ func printDatabaseChanges(databaseChanges string) {
 
 	helpers.LogBold("Database changes:\n" + databaseChanges)
 }
+
+func printFallbackMappingReport(report *dbGen.FallbackMappingReport) {
+	if report.IsEmpty() {
+		helpers.Log("All types have explicit mapping")
+		return
+	}
+
+	helpers.LogWarn("Fallback mapping used for: %s", report.Summary())
+	helpers.LogDebug("Fallback mapping usages:\n%s", report.Details())
+}
+
+// logTarget prints target name when configuration has targets
+func logTarget(config *dbGen.Config) {
+	if config.TargetName != "" {
+		helpers.LogBold("Target %s", config.TargetName)
+	}
+}
+
+// getSingleConfig returns configuration for commands working with one target
+func getSingleConfig(configs []*dbGen.Config) (*dbGen.Config, error) {
+	if len(configs) > 1 {
+		return nil, fmt.Errorf("configuration has %d targets, select one with --target", len(configs))
+	}
+
+	return configs[0], nil
+}
+
+// getProcessedRoutines loads routines of target and maps them the same way as generate
+func getProcessedRoutines(config *dbGen.Config) ([]dbGen.Routine, error) {
+	routines, err := dbGen.GetRoutines(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting routines: %s", err)
+	}
+
+	targetRoutines := dbGen.GetTargetRoutines(routines, config)
+	err = dbGen.PreprocessRoutines(&targetRoutines, config)
+	if err != nil {
+		return nil, fmt.Errorf("error preprocessing: %s", err)
+	}
+
+	processedFunctions, err := dbGen.Process(targetRoutines, config)
+	if err != nil {
+		return nil, fmt.Errorf("error processing: %s", err)
+	}
+
+	return processedFunctions, nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/keenmate/db-gen/private/dbGen"
+	"github.com/keenmate/db-gen/private/helpers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	keyFormat = "format"
+	keySchema = "schema"
+)
+
+var contextFlags = []helpers.FlagArgument{
+	helpers.NewBoolFlag(keyUseRoutinesFile, "", false, "Use routines file to get template data"),
+	helpers.NewStringFlag(keyFormat, "f", dbGen.ContextFormatJson, "Output format (json, yaml)"),
+	helpers.NewBoolFlag(keySchema, "", false, "Print JSON Schema of template data instead of data, database is not used"),
+	targetFlag,
+	variablesFlag,
+}
+
+var contextCmd = &cobra.Command{
+	Use:   "context <scope|output> [item]",
+	Short: "Print template data",
+	Long: `
+	Prints data used to render templates of scope (global, schema, routine, model, enum, customType, jsonModel)
+	or of output, item selects routine, schema, enum, custom type or json model the same way as in render command.
+	Connection string is hidden.
+
+	db-gen context routine public.get_users --useRoutinesFile
+	db-gen context global --format yaml
+	db-gen context model --schema
+	`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helpers.BindFlags(cmd, append(commonFlags, contextFlags...))
+		_, err := dbGen.ReadConfig(viper.GetString(keyConfig))
+		if err != nil {
+			helpers.Exit("configuration error: %s", err)
+		}
+
+		viper.AutomaticEnv() // read in environment variables that match
+
+		itemName := ""
+		if len(args) > 1 {
+			itemName = args[1]
+		}
+
+		err = doContext(args[0], itemName)
+		if err != nil {
+			helpers.Exit(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+
+	helpers.DefineFlags(contextCmd, append(commonFlags, contextFlags...))
+}
+
+func doContext(scopeOrOutput string, itemName string) error {
+	configs, err := dbGen.GetAndValidateConfigs(viper.GetStringSlice(keyTarget))
+	if err != nil {
+		return fmt.Errorf("error getting config %s", err)
+	}
+
+	config, err := getSingleConfig(configs)
+	if err != nil {
+		return err
+	}
+
+	format := viper.GetString(keyFormat)
+	if viper.GetBool(keySchema) {
+		schema, err := dbGen.GetTemplateDataSchema(config, scopeOrOutput, format)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(schema)
+		return nil
+	}
+
+	processedFunctions, err := getProcessedRoutines(config)
+	if err != nil {
+		return err
+	}
+
+	data, err := dbGen.GetTemplateData(config, processedFunctions, scopeOrOutput, itemName, format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(data)
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 
 var databaseChangesFlags = []helpers.FlagArgument{
 	helpers.NewBoolFlag(keyUseRoutinesFile, "", false, "Use routines file to databaseChanges code"),
+	targetFlag,
 }
 
 var databaseChangesCmd = &cobra.Command{
@@ -43,7 +44,7 @@ func init() {
 }
 
 func doDatabaseChanges() error {
-	config, err := dbGen.GetAndValidateConfig()
+	configs, err := dbGen.GetAndValidateConfigs(viper.GetStringSlice(keyTarget))
 	if err != nil {
 		return fmt.Errorf("error getting config %s", err)
 	}
@@ -51,32 +52,46 @@ func doDatabaseChanges() error {
 	helpers.LogDebug("Debug logging is enabled")
 
 	// TODO it will be ideal to load build information before loading and validating config
-	buildInfo, infoExist := dbGen.LoadGenerationInformation(config)
+	buildInfos := make([]*dbGen.GenerationInformation, len(configs))
+	for i, config := range configs {
+		buildInfo, infoExist := dbGen.LoadGenerationInformation(config)
 
-	if !infoExist {
-		return fmt.Errorf("no generation information found")
-	}
+		if !infoExist {
+			if config.TargetName != "" {
+				return fmt.Errorf("no generation information found for target %s", config.TargetName)
+			}
+
+			return fmt.Errorf("no generation information found")
+		}
 
-	if !buildInfo.CheckVersion() {
-		return nil
+		if !buildInfo.CheckVersion() {
+			return nil
+		}
+
+		buildInfos[i] = buildInfo
 	}
 
 	log.Printf("Getting routines...")
-	routines, err := dbGen.GetRoutines(config)
+	routines, err := dbGen.GetRoutines(dbGen.GetIntrospectionConfig(configs))
 	if err != nil {
 		return fmt.Errorf("error getting routines: %s", err)
 	}
 	log.Printf("Got %d routines", len(routines))
 
-	err = dbGen.PreprocessRoutines(&routines, config)
-	if err != nil {
-		return fmt.Errorf("error preprocessing routines: %s", err)
-	}
+	for i, config := range configs {
+		targetRoutines := dbGen.GetTargetRoutines(routines, config)
 
-	log.Printf("Routines preprocessed")
+		err = dbGen.PreprocessRoutines(&targetRoutines, config)
+		if err != nil {
+			return fmt.Errorf("error preprocessing routines: %s", err)
+		}
 
-	databaseChanges := buildInfo.GetRoutinesChanges(routines)
-	printDatabaseChanges(databaseChanges)
+		log.Printf("Routines preprocessed")
+
+		logTarget(config)
+		databaseChanges := buildInfos[i].GetRoutinesChanges(targetRoutines)
+		printDatabaseChanges(databaseChanges)
+	}
 
 	return nil
 }
@@ -10,10 +10,22 @@ import (
 	"log"
 )
 
-const keyUseRoutinesFile = "useRoutinesFile"
+const (
+	keyUseRoutinesFile = "useRoutinesFile"
+	keyDryRun          = "dry-run"
+	keyDiff            = "diff"
+	keyCheck           = "check"
+	keyJobs            = "jobs"
+)
 
 var generateFlags = []helpers.FlagArgument{
 	helpers.NewBoolFlag(keyUseRoutinesFile, "", false, "Use routines file to generate code"),
+	helpers.NewBoolFlag(keyDryRun, "", false, "Print files that would be created, modified or deleted without writing anything"),
+	helpers.NewBoolFlag(keyCheck, "", false, "Fail when generated files differ from output folder, nothing is written"),
+	helpers.NewBoolFlag(keyDiff, "", false, "Print unified diff of every change, used with --dry-run and --check"),
+	helpers.NewIntFlag(keyJobs, "j", 0, "Number of files rendered in parallel, number of CPUs if not set"),
+	targetFlag,
+	variablesFlag,
 }
 
 var generateCmd = &cobra.Command{
@@ -23,6 +35,10 @@ var generateCmd = &cobra.Command{
 	Generate code for calling stored procedures.
 	Procedures can be loaded from database or from provided file.
 	Output folder and templates are defined in configuration file.
+	With --dry-run, files are only rendered and compared with output folder,
+	neither output folder nor generation information is changed.
+	With --check, generate fails when any file is stale, missing or orphaned, so CI can verify
+	that generated code is up to date.
 	
 	For more information, see github.com/keenmate/db-gen
 
@@ -47,13 +63,14 @@ func init() {
 	rootCmd.AddCommand(generateCmd)
 
 	helpers.DefineFlags(generateCmd, append(commonFlags, generateFlags...))
+	generateCmd.MarkFlagsMutuallyExclusive(keyDryRun, keyCheck)
 }
 
 func doGenerate() error {
 	timer := helpers.NewTimer()
 	log.Printf("Getting configurations...")
 
-	config, err := dbGen.GetAndValidateConfig()
+	configs, err := dbGen.GetAndValidateConfigs(viper.GetStringSlice(keyTarget))
 	if err != nil {
 		return fmt.Errorf("error getting config %s", err)
 	}
@@ -61,29 +78,41 @@ func doGenerate() error {
 	helpers.LogDebug("Debug logging is enabled")
 	timer.AddEntry("getting config")
 
+	dryRun := viper.GetBool(keyDryRun)
+	check := viper.GetBool(keyCheck)
+
 	// TODO it will be ideal to load build information before loading and validating config
-	buildInfo, infoExist := dbGen.LoadGenerationInformation(config)
-	timer.AddEntry("loading build information")
+	buildInfos := make([]*dbGen.GenerationInformation, len(configs))
+	for i, config := range configs {
+		buildInfo, infoExist := dbGen.LoadGenerationInformation(config)
+		if !infoExist {
+			continue
+		}
 
-	if infoExist {
+		logTarget(config)
 		log.Printf("Build information loaded, last build was at %s", buildInfo.Time.String())
 
-		if !buildInfo.CheckVersion() {
+		// dry run and check don't write anything, so there is nothing to confirm
+		if !dryRun && !check && !buildInfo.CheckVersion() {
 			return nil
 		}
+
+		buildInfos[i] = buildInfo
 	}
+	timer.AddEntry("loading build information")
 
-	var routines []dbGen.DbRoutine
+	// routines of all targets are loaded at once
+	introspectionConfig := dbGen.GetIntrospectionConfig(configs)
 
 	log.Printf("Getting routines...")
-	routines, err = dbGen.GetRoutines(config)
+	routines, err := dbGen.GetRoutines(introspectionConfig)
 	if err != nil {
 		return fmt.Errorf("error getting routines: %s", err)
 	}
 	log.Printf("Got %d routines", len(routines))
 
 	timer.AddEntry("getting routines")
-	if config.Debug {
+	if introspectionConfig.Debug {
 		helpers.LogDebug("Saving to debug file...")
 		err = helpers.SaveToTempFile(routines, "dbRoutines")
 		if err != nil {
@@ -92,14 +121,59 @@ func doGenerate() error {
 		timer.AddEntry("saving debug file")
 	}
 
+	outdatedFiles := 0
+	reports := make([]*dbGen.FallbackMappingReport, len(configs))
+	for i, config := range configs {
+		logTarget(config)
+
+		var plan []dbGen.PlannedFile
+		reports[i], plan, err = generateTarget(config, buildInfos[i], dbGen.GetTargetRoutines(routines, config), dryRun || check, timer)
+		if err != nil {
+			if config.TargetName != "" {
+				return fmt.Errorf("target %s: %s", config.TargetName, err)
+			}
+
+			return err
+		}
+
+		if dryRun {
+			printGeneratePlan(plan)
+		}
+
+		if check {
+			outdatedFiles += printOutdatedFiles(plan)
+		}
+	}
+
+	timer.Finish()
+	log.Printf(timer.String())
+
+	for i, report := range reports {
+		logTarget(configs[i])
+		printFallbackMappingReport(report)
+	}
+
+	if check {
+		if outdatedFiles > 0 {
+			return fmt.Errorf("%d generated files are not up to date, run generate", outdatedFiles)
+		}
+
+		helpers.Log("Generated files are up to date")
+	}
+
+	return nil
+}
+
+// generateTarget generates files of target, with planOnly nothing is written and plan of changes is returned instead
+func generateTarget(config *dbGen.Config, buildInfo *dbGen.GenerationInformation, routines []dbGen.DbRoutine, planOnly bool, timer *helpers.Timer) (*dbGen.FallbackMappingReport, []dbGen.PlannedFile, error) {
 	// mark function as overloads
 	log.Printf("Preprocessing...")
-	err = dbGen.PreprocessRoutines(&routines, config)
+	err := dbGen.PreprocessRoutines(&routines, config)
 	if err != nil {
-		return fmt.Errorf("error preprocessing: %s", err)
+		return nil, nil, fmt.Errorf("error preprocessing: %s", err)
 	}
 
-	if infoExist {
+	if buildInfo != nil {
 		// TODO maybe only handle changes after filtering
 		changes := buildInfo.GetRoutinesChanges(routines)
 		printDatabaseChanges(changes)
@@ -110,7 +184,7 @@ func doGenerate() error {
 	log.Printf("Processing...")
 	processedFunctions, err := dbGen.Process(routines, config)
 	if err != nil {
-		return fmt.Errorf("error preprocessing: %s", err)
+		return nil, nil, fmt.Errorf("error preprocessing: %s", err)
 	}
 	log.Printf("After preprocessing %d - %d = %d functions left", len(routines), len(routines)-len(processedFunctions), len(processedFunctions))
 	timer.AddEntry("preprocessing")
@@ -119,26 +193,78 @@ func doGenerate() error {
 		helpers.LogDebug("Saving to debug file...")
 		err = helpers.SaveToTempFile(processedFunctions, "mapped")
 		if err != nil {
-			return fmt.Errorf("error saving debug file: %s", err)
+			return nil, nil, fmt.Errorf("error saving debug file: %s", err)
 		}
 		timer.AddEntry("saving debug file")
 
 	}
 
+	if planOnly {
+		log.Printf("Planning...")
+		plan, err := dbGen.PlanGenerate(processedFunctions, config, buildInfo, viper.GetBool(keyDiff))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error planning: %s", err)
+		}
+		timer.AddEntry("planning files")
+
+		return dbGen.GetFallbackMappingReport(processedFunctions), plan, nil
+	}
+
 	log.Printf("Generating...")
-	err = dbGen.Generate(processedFunctions, config)
+	files, err := dbGen.Generate(processedFunctions, config, buildInfo)
 	if err != nil {
-		return fmt.Errorf("error generating: %s", err)
+		return nil, nil, fmt.Errorf("error generating: %s", err)
 	}
 	timer.AddEntry("generating files")
 
-	err = dbGen.SaveGenerationInformation(config, routines, version.GetVersion())
+	// without generation information, files that stop being generated would never be deleted
+	err = dbGen.SaveGenerationInformation(config, routines, files, version.GetVersion())
 	if err != nil {
-		log.Printf("Error saving generation information: %v", err)
+		return nil, nil, fmt.Errorf("files were generated, but orphaned files won't be deleted by next run: %s", err)
 	}
 
 	timer.AddEntry("saving generation info")
-	timer.Finish()
-	log.Printf(timer.String())
-	return nil
+
+	return dbGen.GetFallbackMappingReport(processedFunctions), nil, nil
+}
+
+func printGeneratePlan(plan []dbGen.PlannedFile) {
+	counts := make(map[string]int)
+	for _, file := range plan {
+		counts[file.Action]++
+
+		if file.Action == dbGen.PlanUnchanged {
+			helpers.LogDebug("%-9s %s", file.Action, file.Path)
+			continue
+		}
+
+		fmt.Printf("%-9s %s\n", file.Action, file.Path)
+		fmt.Print(file.Diff)
+	}
+
+	helpers.LogBold("Plan: %d to create, %d to modify, %d unchanged, %d to delete",
+		counts[dbGen.PlanCreate], counts[dbGen.PlanModify], counts[dbGen.PlanUnchanged], counts[dbGen.PlanDelete])
+}
+
+// printOutdatedFiles prints files that differ from output folder and returns their count
+func printOutdatedFiles(plan []dbGen.PlannedFile) int {
+	states := map[string]string{
+		dbGen.PlanCreate: "missing",
+		dbGen.PlanModify: "stale",
+		dbGen.PlanDelete: "orphaned",
+	}
+
+	outdated := 0
+	for _, file := range plan {
+		state, isOutdated := states[file.Action]
+		if !isOutdated {
+			continue
+		}
+
+		outdated++
+		fmt.Printf("%-9s %s\n", state, file.Path)
+		fmt.Print(file.Diff)
+	}
+
+	return outdated
 }
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/keenmate/db-gen/private/dbGen"
+	"github.com/keenmate/db-gen/private/helpers"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var mappingPresetsCmd = &cobra.Command{
+	Use:   "mapping-presets [name]",
+	Short: "List or print built-in mapping presets",
+	Long: `
+	Without arguments lists names of built-in mapping presets.
+	With preset name prints the preset, so it can be copied to configuration
+	and versioned together with your project.
+
+	db-gen mapping-presets go-pgx > mappings.json
+	`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		if len(args) == 0 {
+			err = doListMappingPresets()
+		} else {
+			err = doPrintMappingPreset(args[0])
+		}
+
+		if err != nil {
+			helpers.Exit(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mappingPresetsCmd)
+}
+
+func doListMappingPresets() error {
+	names, err := dbGen.GetMappingPresetNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+
+	return nil
+}
+
+func doPrintMappingPreset(name string) error {
+	content, err := dbGen.GetMappingPresetContent(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(content)
+	return err
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/keenmate/db-gen/private/dbGen"
+	"github.com/keenmate/db-gen/private/helpers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const keyScope = "scope"
+
+var renderFlags = []helpers.FlagArgument{
+	helpers.NewBoolFlag(keyUseRoutinesFile, "", false, "Use routines file to render template"),
+	helpers.NewStringFlag(keyScope, "", "", "Scope of template file (global, schema, routine, model, enum, customType, jsonModel)"),
+	targetFlag,
+	variablesFlag,
+}
+
+var renderCmd = &cobra.Command{
+	Use:   "render <template|output> [item]",
+	Short: "Render template to standard output",
+	Long: `
+	Renders one template for one item and prints it, no file is written.
+	First argument is name of output from configuration or path to template file,
+	item is routine (schema.name, name or mapped function name), schema, enum, custom type or json model by scope.
+
+	Template file is rendered with routine scope when item is set and with global scope otherwise,
+	use --scope to change it. Filter of output is ignored.
+
+	db-gen render ./templates/model.gotmpl public.get_users --useRoutinesFile
+	db-gen render dbcontext
+	`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helpers.BindFlags(cmd, append(commonFlags, renderFlags...))
+		_, err := dbGen.ReadConfig(viper.GetString(keyConfig))
+		if err != nil {
+			helpers.Exit("configuration error: %s", err)
+		}
+
+		viper.AutomaticEnv() // read in environment variables that match
+
+		itemName := ""
+		if len(args) > 1 {
+			itemName = args[1]
+		}
+
+		err = doRender(args[0], itemName)
+		if err != nil {
+			helpers.Exit(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+
+	helpers.DefineFlags(renderCmd, append(commonFlags, renderFlags...))
+}
+
+func doRender(templateOrOutput string, itemName string) error {
+	configs, err := dbGen.GetAndValidateConfigs(viper.GetStringSlice(keyTarget))
+	if err != nil {
+		return fmt.Errorf("error getting config %s", err)
+	}
+
+	config, err := getSingleConfig(configs)
+	if err != nil {
+		return err
+	}
+
+	processedFunctions, err := getProcessedRoutines(config)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := dbGen.RenderTemplate(config, processedFunctions, templateOrOutput, viper.GetString(keyScope), itemName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(rendered)
+	return nil
+}
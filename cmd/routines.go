@@ -9,12 +9,21 @@ import (
 	"log"
 )
 
+// routines file is shared by all targets, so routines of all targets are always saved
+var getRoutinesFlags = []helpers.FlagArgument{
+	helpers.NewStringSliceFlag(keyTarget, "t", nil, "Not supported, routines of all targets are saved to one routines file"),
+}
+
 var getRoutinesCmd = &cobra.Command{
 	Use:   "routines [out]",
 	Short: "Get routines",
 	Long:  "Get routines from database and save them to file to generate later",
 	Run: func(cmd *cobra.Command, args []string) {
-		helpers.BindFlags(cmd, commonFlags)
+		helpers.BindFlags(cmd, append(commonFlags, getRoutinesFlags...))
+
+		if len(viper.GetStringSlice(keyTarget)) > 0 {
+			helpers.Exit("--target can't be used with routines, routines file is shared by all targets and contains routines of all of them")
+		}
 
 		configLocation := viper.GetString("config")
 
@@ -42,19 +51,22 @@ var getRoutinesCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(getRoutinesCmd)
 
-	helpers.DefineFlags(getRoutinesCmd, commonFlags)
+	helpers.DefineFlags(getRoutinesCmd, append(commonFlags, getRoutinesFlags...))
 }
 
 func doGetRoutines() error {
 	log.Printf("Getting configurations...")
 
-	config, err := dbGen.GetAndValidateConfig()
+	configs, err := dbGen.GetAndValidateConfigs(nil)
 	if err != nil {
 		return fmt.Errorf("error getting config %s", err)
 	}
 
 	helpers.LogDebug("Debug logging is enabled")
 
+	// routines of all targets are saved to one file
+	config := dbGen.GetIntrospectionConfig(configs)
+
 	// because we use shared config, we need to set this to force loading from database
 	config.UseRoutinesFile = false
 
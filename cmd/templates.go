@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/keenmate/db-gen/private/dbGen"
+	"github.com/keenmate/db-gen/private/helpers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Work with template packs and templates",
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List built-in template packs",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := doListTemplatePacks()
+		if err != nil {
+			helpers.Exit(err.Error())
+		}
+	},
+}
+
+var templatesExtractCmd = &cobra.Command{
+	Use:   "extract <pack> <folder>",
+	Short: "Copy built-in template pack to folder",
+	Long: `
+	Copies templates and pack.json of built-in template pack to folder,
+	so they can be customized and versioned together with your project.
+	Extracted pack is used by setting TemplatePack to the folder.
+
+	db-gen templates extract go-pgx ./templates
+	`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+
+		err := doExtractTemplatePack(args[0], args[1], force)
+		if err != nil {
+			helpers.Exit(err.Error())
+		}
+	},
+}
+
+var templatesLintFlags = []helpers.FlagArgument{
+	targetFlag,
+}
+
+var templatesLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check templates of configured outputs",
+	Long: `
+	Checks templates, partials, filters and paths of all outputs without connecting to database.
+	Fields are checked against template data of output scope,
+	functions and templates used with template or include have to exist.
+	Errors are printed as file:line:column: message.
+	`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		helpers.BindFlags(cmd, append(commonFlags, templatesLintFlags...))
+		_, err := dbGen.ReadConfig(viper.GetString(keyConfig))
+		if err != nil {
+			helpers.Exit("configuration error: %s", err)
+		}
+
+		err = doLintTemplates()
+		if err != nil {
+			helpers.Exit(err.Error())
+		}
+	},
+}
+
+var templatesBlocksFlags = []helpers.FlagArgument{
+	targetFlag,
+}
+
+var templatesBlocksCmd = &cobra.Command{
+	Use:   "blocks [output]",
+	Short: "Show blocks of output templates and where they are defined",
+	Long: `
+	Lists body and {{define}} blocks of templates of all outputs (or one output) after partials,
+	template and TemplateOverrides are combined. Layer shows which one the used block comes from:
+	partial, template (template of output, e.g. template pack) or override.
+
+	db-gen templates blocks models
+	`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helpers.BindFlags(cmd, append(commonFlags, templatesBlocksFlags...))
+		_, err := dbGen.ReadConfig(viper.GetString(keyConfig))
+		if err != nil {
+			helpers.Exit("configuration error: %s", err)
+		}
+
+		outputName := ""
+		if len(args) > 0 {
+			outputName = args[0]
+		}
+
+		err = doShowTemplateBlocks(outputName)
+		if err != nil {
+			helpers.Exit(err.Error())
+		}
+	},
+}
+
+const keyUpdate = "update"
+
+var templatesTestFlags = []helpers.FlagArgument{
+	targetFlag,
+	helpers.NewBoolFlag(keyUpdate, "u", false, "Update expected files with generated files"),
+}
+
+var templatesTestCmd = &cobra.Command{
+	Use:   "test <fixtures>",
+	Short: "Test templates against expected files",
+	Long: `
+	Generates files from routines files of fixtures and compares them with expected files, no database is needed.
+	Every subfolder of fixtures folder with routines.json is fixture, its expected files are in expected folder
+	(expected/<target> when configuration has targets). Routines file is created by routines command.
+	Differences are printed as unified diff, --update rewrites expected files with generated files.
+
+	db-gen templates test ./fixtures
+	`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helpers.BindFlags(cmd, append(commonFlags, templatesTestFlags...))
+		_, err := dbGen.ReadConfig(viper.GetString(keyConfig))
+		if err != nil {
+			helpers.Exit("configuration error: %s", err)
+		}
+
+		err = doTestTemplates(args[0], viper.GetBool(keyUpdate))
+		if err != nil {
+			helpers.Exit(err.Error())
+		}
+	},
+}
+
+func init() {
+	helpers.DefineFlags(templatesLintCmd, append(commonFlags, templatesLintFlags...))
+	helpers.DefineFlags(templatesTestCmd, append(commonFlags, templatesTestFlags...))
+	helpers.DefineFlags(templatesBlocksCmd, append(commonFlags, templatesBlocksFlags...))
+	templatesExtractCmd.Flags().Bool("force", false, "Overwrite existing files")
+
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesExtractCmd)
+	templatesCmd.AddCommand(templatesLintCmd)
+	templatesCmd.AddCommand(templatesTestCmd)
+	templatesCmd.AddCommand(templatesBlocksCmd)
+	rootCmd.AddCommand(templatesCmd)
+}
+
+func doListTemplatePacks() error {
+	names, err := dbGen.GetTemplatePackNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		pack, err := dbGen.GetTemplatePack(name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-20s %s\n", name, pack.Description)
+	}
+
+	return nil
+}
+
+func doExtractTemplatePack(name string, folder string, force bool) error {
+	files, err := dbGen.ExtractTemplatePack(name, folder, force)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		helpers.Log("Created: %s", file)
+	}
+
+	return nil
+}
+
+func doLintTemplates() error {
+	configs, err := dbGen.GetAndValidateConfigs(viper.GetStringSlice(keyTarget))
+	if err != nil {
+		return fmt.Errorf("error getting config %s", err)
+	}
+
+	errorCount := 0
+	for _, config := range configs {
+		logTarget(config)
+
+		lintErrors, err := dbGen.LintTemplates(config)
+		if err != nil {
+			return err
+		}
+
+		for _, lintError := range lintErrors {
+			fmt.Println(lintError.String())
+		}
+
+		errorCount += len(lintErrors)
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("found %d template errors", errorCount)
+	}
+
+	helpers.Log("No template errors found")
+	return nil
+}
+
+func doTestTemplates(fixturesFolder string, update bool) error {
+	configs, err := dbGen.GetAndValidateConfigs(viper.GetStringSlice(keyTarget))
+	if err != nil {
+		return fmt.Errorf("error getting config %s", err)
+	}
+
+	total, failed := 0, 0
+	for _, config := range configs {
+		logTarget(config)
+
+		results, err := dbGen.RunTemplateTests(config, fixturesFolder, update)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			total++
+
+			switch {
+			case update:
+				helpers.Log("UPDATED %s (%d files)", result.Fixture, len(result.Updated))
+				for _, file := range result.Updated {
+					helpers.LogDebug("Updated: %s", file)
+				}
+			case result.Passed():
+				helpers.Log("PASS %s", result.Fixture)
+			default:
+				failed++
+				helpers.LogWarn("FAIL %s", result.Fixture)
+				for _, diff := range result.Diffs {
+					fmt.Print(diff)
+				}
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures failed", failed, total)
+	}
+
+	if !update {
+		helpers.Log("All %d fixtures passed", total)
+	}
+	return nil
+}
+
+func doShowTemplateBlocks(outputName string) error {
+	configs, err := dbGen.GetAndValidateConfigs(viper.GetStringSlice(keyTarget))
+	if err != nil {
+		return fmt.Errorf("error getting config %s", err)
+	}
+
+	for _, config := range configs {
+		logTarget(config)
+
+		blocks, err := dbGen.GetTemplateBlocks(config, outputName)
+		if err != nil {
+			return err
+		}
+
+		lastOutput := ""
+		for _, block := range blocks {
+			if block.Output != lastOutput {
+				helpers.LogBold("Output %s", block.Output)
+				lastOutput = block.Output
+			}
+
+			fmt.Printf("  %-30s %-9s %s\n", block.Name, block.Layer, block.Location)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package dbGen
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/guregu/null/v5"
 	common2 "github.com/keenmate/db-gen/private/helpers"
@@ -16,25 +17,37 @@ var localPrefixes = []string{"local.", ".local."}
 var localPostfixes = []string{".local"}
 
 type Config struct {
-	PathBase                         string         //for now just using config folder
-	ConnectionString                 string         `mapstructure:"ConnectionString"`
-	OutputFolder                     string         `mapstructure:"OutputFolder"`
-	ProcessorsFolderName             string         `mapstructure:"ProcessorsFolderName"`
-	ModelsFolderName                 string         `mapstructure:"ModelsFolderName"`
-	GenerateModels                   bool           `mapstructure:"GenerateModels"`
-	GenerateProcessors               bool           `mapstructure:"GenerateProcessors"`
-	GenerateProcessorsForVoidReturns bool           `mapstructure:"GenerateProcessorsForVoidReturns"`
-	DbContextTemplate                string         `mapstructure:"DbContextTemplate"`
-	ModelTemplate                    string         `mapstructure:"ModelTemplate"`
-	ProcessorTemplate                string         `mapstructure:"ProcessorTemplate"`
-	GeneratedFileExtension           string         `mapstructure:"GeneratedFileExtension"`
-	GeneratedFileCase                string         `mapstructure:"GeneratedFileCase"`
-	Debug                            bool           `mapstructure:"Debug"`
-	ClearOutputFolder                bool           `mapstructure:"ClearOutputFolder"`
-	RoutinesFile                     string         `mapstructure:"RoutinesFile"`
-	UseRoutinesFile                  bool           `mapstructure:"UseRoutinesFile"`
-	Generate                         []SchemaConfig `mapstructure:"Generate"`
-	Mappings                         []Mapping      `mapstructure:"Mappings"`
+	PathBase                         string                   //for now just using config folder
+	ConnectionString                 string                   `mapstructure:"ConnectionString"`
+	OutputFolder                     string                   `mapstructure:"OutputFolder"`
+	ProcessorsFolderName             string                   `mapstructure:"ProcessorsFolderName"`
+	ModelsFolderName                 string                   `mapstructure:"ModelsFolderName"`
+	GenerateModels                   bool                     `mapstructure:"GenerateModels"`
+	GenerateProcessors               bool                     `mapstructure:"GenerateProcessors"`
+	GenerateProcessorsForVoidReturns bool                     `mapstructure:"GenerateProcessorsForVoidReturns"`
+	DbContextTemplate                string                   `mapstructure:"DbContextTemplate"`
+	ModelTemplate                    string                   `mapstructure:"ModelTemplate"`
+	ProcessorTemplate                string                   `mapstructure:"ProcessorTemplate"`
+	JsonModelTemplate                string                   `mapstructure:"JsonModelTemplate"`
+	TemplatePack                     string                   `mapstructure:"TemplatePack"`      // builtin:<name> or path to folder with pack.json
+	TemplatePartials                 string                   `mapstructure:"TemplatePartials"`  // folder or glob of files with shared {{define}} blocks
+	TemplateOverrides                string                   `mapstructure:"TemplateOverrides"` // folder with files overriding blocks or templates of TemplatePack
+	Variables                        map[string]interface{}   `mapstructure:"Variables"`         // available in templates as .Vars
+	Outputs                          []OutputConfig           `mapstructure:"Outputs"`
+	GeneratedFileExtension           string                   `mapstructure:"GeneratedFileExtension"`
+	GeneratedFileCase                string                   `mapstructure:"GeneratedFileCase"`
+	Debug                            bool                     `mapstructure:"Debug"`
+	Jobs                             int                      `mapstructure:"Jobs"` // items rendered in parallel, number of CPUs if not set
+	ClearOutputFolder                bool                     `mapstructure:"ClearOutputFolder"`
+	RoutinesFile                     string                   `mapstructure:"RoutinesFile"`
+	UseRoutinesFile                  bool                     `mapstructure:"UseRoutinesFile"`
+	Generate                         []SchemaConfig           `mapstructure:"Generate"`
+	MappingPresets                   []string                 `mapstructure:"MappingPresets"`
+	Mappings                         []Mapping                `mapstructure:"Mappings"`
+	StrictMappings                   bool                     `mapstructure:"StrictMappings"`
+	Targets                          []map[string]interface{} `mapstructure:"Targets"`
+	TargetName                       string                   // set when configuration is generated as one of Targets
+	presetMappingCount               int                      // Mappings loaded from MappingPresets, they are before project mappings
 }
 
 type SchemaConfig struct {
@@ -48,24 +61,34 @@ type RoutineMapping struct {
 	MappedName          string                   `mapstructure:"MappedName"`
 	DontRetrieveValues  bool                     `mapstructure:"DontRetrieveValues"`
 	SelectOnlySpecified bool                     `mapstructure:"SelectOnlySpecified"`
+	TypeParameters      map[string]string        `mapstructure:"TypeParameters"`
 	Model               map[string]ColumnMapping `mapstructure:"Model"`
 	Parameters          map[string]ParamMapping  `mapstructure:"Parameters"`
+	Tags                []string                 `mapstructure:"Tags"`
+	Extra               map[string]interface{}   `mapstructure:"Extra"`
+	generateIsSet       bool                     // Generate set by bool value or in object, pattern without it doesn't affect generation
 }
 
 type ColumnMapping struct {
 	SelectColumn    bool
-	MappedName      string `mapstructure:"MappedName"`
-	MappedType      string `mapstructure:"MappedType"`
-	MappingFunction string `mapstructure:"MappingFunction"`
-
-	IsNullable null.Bool `mapstructure:"IsNullable"`
+	MappedName      string      `mapstructure:"MappedName"`
+	MappedType      string      `mapstructure:"MappedType"`
+	MappingFunction string      `mapstructure:"MappingFunction"`
+	JsonSchema      interface{} `mapstructure:"JsonSchema"` // inline schema or path to schema file
+
+	IsNullable null.Bool              `mapstructure:"IsNullable"`
+	Tags       []string               `mapstructure:"Tags"`
+	Extra      map[string]interface{} `mapstructure:"Extra"`
 }
 
 type ParamMapping struct {
-	MappedName string    `mapstructure:"MappedName"`
-	MappedType string    `mapstructure:"MappedType"`
-	IsNullable null.Bool `mapstructure:"IsNullable"`
-	IsOptional null.Bool `mapstructure:"IsOptional"`
+	MappedName string                 `mapstructure:"MappedName"`
+	MappedType string                 `mapstructure:"MappedType"`
+	IsNullable null.Bool              `mapstructure:"IsNullable"`
+	IsOptional null.Bool              `mapstructure:"IsOptional"`
+	JsonSchema interface{}            `mapstructure:"JsonSchema"` // inline schema or path to schema file
+	Tags       []string               `mapstructure:"Tags"`
+	Extra      map[string]interface{} `mapstructure:"Extra"`
 }
 
 type Mapping struct {
@@ -77,8 +100,8 @@ type Mapping struct {
 // set in ReadConfig
 var loadedConfigLocation = ""
 
-// GetAndValidateConfig gets configuration from viper
-func GetAndValidateConfig() (*Config, error) {
+// GetAndValidateConfigs gets configuration of selected targets from viper, configuration without targets is returned as only target
+func GetAndValidateConfigs(targetNames []string) ([]*Config, error) {
 	config := &Config{
 		PathBase:                         "",
 		ConnectionString:                 "",
@@ -91,14 +114,24 @@ func GetAndValidateConfig() (*Config, error) {
 		DbContextTemplate:                "",
 		ModelTemplate:                    "",
 		ProcessorTemplate:                "",
+		JsonModelTemplate:                "",
+		TemplatePack:                     "",
+		TemplatePartials:                 "",
+		TemplateOverrides:                "",
+		Variables:                        nil,
+		Outputs:                          nil,
 		GeneratedFileExtension:           "",
 		GeneratedFileCase:                "",
 		Debug:                            false,
+		Jobs:                             0,
 		ClearOutputFolder:                false,
 		Generate:                         nil,
+		MappingPresets:                   nil,
 		Mappings:                         nil,
+		StrictMappings:                   false,
 		RoutinesFile:                     "./db-gen-routines.json",
 		UseRoutinesFile:                  false,
+		Targets:                          nil,
 	}
 
 	err := getConfigFromViper(config)
@@ -110,36 +143,99 @@ func GetAndValidateConfig() (*Config, error) {
 	if loadedConfigLocation == "" {
 		return nil, fmt.Errorf("no configuration file loaded")
 	}
+
+	restoreJsonSchemas(config)
+	restoreVariables(config)
+	restoreExtras(config)
 	// set in TryReadConfigFile
 	config.PathBase = filepath.Dir(loadedConfigLocation)
 
+	targets, err := getTargetConfigs(config, targetNames)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, target := range targets {
+		err = resolveConfig(target)
+		if err != nil {
+			if target.TargetName != "" {
+				return nil, fmt.Errorf("target %s: %s", target.TargetName, err)
+			}
+
+			return nil, err
+		}
+	}
+
+	return targets, nil
+}
+
+// resolveConfig resolves paths, mappings and outputs and validates configuration
+func resolveConfig(config *Config) error {
 	//All paths are relative to basePath(config file folder)
-	config.ProcessorTemplate = joinIfRelative(config.PathBase, config.ProcessorTemplate)
-	config.DbContextTemplate = joinIfRelative(config.PathBase, config.DbContextTemplate)
-	config.ModelTemplate = joinIfRelative(config.PathBase, config.ModelTemplate)
+	config.ProcessorTemplate = joinIfNotEmpty(config.PathBase, config.ProcessorTemplate)
+	config.DbContextTemplate = joinIfNotEmpty(config.PathBase, config.DbContextTemplate)
+	config.ModelTemplate = joinIfNotEmpty(config.PathBase, config.ModelTemplate)
+	config.JsonModelTemplate = joinIfNotEmpty(config.PathBase, config.JsonModelTemplate)
+	config.TemplatePartials = joinIfNotEmpty(config.PathBase, config.TemplatePartials)
+	config.TemplateOverrides = joinIfNotEmpty(config.PathBase, config.TemplateOverrides)
 
 	config.OutputFolder = joinIfRelative(config.PathBase, config.OutputFolder)
 	// TODO maybe it is better to be relative to Output folder, not Base path
 	config.RoutinesFile = joinIfRelative(config.PathBase, config.RoutinesFile)
 
+	packOutputs, err := applyTemplatePack(config)
+	if err != nil {
+		return err
+	}
+
+	err = applyCommandLineVariables(config)
+	if err != nil {
+		return err
+	}
+
 	config.GeneratedFileCase = strings.ToLower(config.GeneratedFileCase)
 
+	// project mappings are layered on top of presets
+	projectMappingCount := len(config.Mappings)
+	config.Mappings, err = applyMappingPresets(config.MappingPresets, config.Mappings)
+	if err != nil {
+		return fmt.Errorf("applying mapping presets: %s", err)
+	}
+	config.presetMappingCount = len(config.Mappings) - projectMappingCount
+
 	if !common2.Contains(ValidCaseNormalized, config.GeneratedFileCase) {
-		return nil, fmt.Errorf(" '%s' is not valid case (maybe GeneratedFileCase is missing)", config.GeneratedFileCase)
+		return fmt.Errorf(" '%s' is not valid case (maybe GeneratedFileCase is missing)", config.GeneratedFileCase)
+	}
+
+	// legacy template settings are generated first, then template pack and project outputs
+	config.Outputs = append(append(getLegacyOutputs(config), packOutputs...), normalizeOutputs(config.Outputs, config.PathBase)...)
+
+	err = validateOutputs(config.Outputs)
+	if err != nil {
+		return fmt.Errorf("invalid outputs: %s", err)
 	}
 
 	common2.LogDebug("Loaded configuration: \n%+v", config)
-	return config, nil
+	return nil
 }
 
 func joinIfRelative(basePath string, joiningPath string) string {
-	if filepath.IsAbs(joiningPath) {
+	if filepath.IsAbs(joiningPath) || isBuiltinPath(joiningPath) {
 		return joiningPath
 	}
 
 	return filepath.Join(basePath, joiningPath)
 }
 
+// joinIfNotEmpty keeps unset paths empty
+func joinIfNotEmpty(basePath string, joiningPath string) string {
+	if joiningPath == "" {
+		return ""
+	}
+
+	return joinIfRelative(basePath, joiningPath)
+}
+
 func ReadConfig(configLocation string) (string, error) {
 	// TODO refactor out duplicit code
 
@@ -223,18 +319,23 @@ func TryReadConfigFile(configPath string) (bool, error) {
 		return false, nil
 	}
 
-	file, err := os.Open(configPath)
-	defer file.Close()
+	content, err := os.ReadFile(configPath)
 	if err != nil {
 		return true, fmt.Errorf("opening file: %s", err)
 	}
 
-	viper.SetConfigType(filepath.Ext(configPath)[1:])
+	configType := filepath.Ext(configPath)[1:]
+	viper.SetConfigType(configType)
 
-	err = viper.MergeConfig(file)
+	err = viper.MergeConfig(bytes.NewReader(content))
 	if err != nil {
 		return true, fmt.Errorf("reading configuration: %s", err)
 	}
+
+	err = mergeRawConfig(content, configType)
+	if err != nil {
+		return true, err
+	}
 	common2.LogDebug("Configuration file at %s loaded", configPath)
 
 	return true, nil
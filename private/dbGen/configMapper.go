@@ -21,9 +21,18 @@ func getConfigFromViper(configWithDefaultValue *Config) error {
 }
 
 func decodeWithHook(in interface{}, out interface{}) error {
+	return decode(in, out, false)
+}
+
+// decodeOverWithHook replaces values in out, maps and slices set in input are not merged with existing values
+func decodeOverWithHook(in interface{}, out interface{}) error {
+	return decode(in, out, true)
+}
+
+func decode(in interface{}, out interface{}, zeroFields bool) error {
 	decodeHook := mapstructure.ComposeDecodeHookFunc(
 		mapCustomTypes())
-	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{DecodeHook: decodeHook, Result: out})
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{DecodeHook: decodeHook, Result: out, ZeroFields: zeroFields})
 	if err != nil {
 		return err
 	}
@@ -68,8 +77,12 @@ func decodeFunctionMap(data interface{}) (interface{}, error) {
 		if reflect.ValueOf(functionMapping).Kind() == reflect.Bool {
 			// simple processing value
 			mappedValue.Generate = functionMapping.(bool)
+			mappedValue.generateIsSet = true
 
 		} else {
+			if rawMapping, isMap := functionMapping.(map[string]interface{}); isMap {
+				_, mappedValue.generateIsSet = findRawKey(rawMapping, "Generate")
+			}
 
 			err := decodeWithHook(functionMapping, mappedValue)
 			if err != nil {
@@ -78,6 +91,13 @@ func decodeFunctionMap(data interface{}) (interface{}, error) {
 
 		}
 
+		if isFunctionPattern(dbFunctionName) {
+			err := validatePatternMapping(dbFunctionName, mappedValue)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		mappings[dbFunctionName] = *mappedValue
 
 	}
@@ -108,6 +128,10 @@ func decodeColumnMapping(data interface{}) (interface{}, error) {
 			if mappedValue.MappedType == "" && mappedValue.MappingFunction != "" {
 				return nil, fmt.Errorf("cant set mapping function mapping without setting mapped type")
 			}
+
+			if mappedValue.MappedType != "" && mappedValue.JsonSchema != nil {
+				return nil, fmt.Errorf("cant set both mapped type and json schema, type is generated from json schema")
+			}
 		}
 
 		columns[dbFunctionName] = *mappedValue
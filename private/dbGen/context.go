@@ -0,0 +1,201 @@
+package dbGen
+
+import (
+	"fmt"
+	"github.com/guregu/null/v5"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Template data of any scope can be printed, so template authors can see what is available.
+// Printed data is the same as data used in templates, only connection string is hidden
+
+const (
+	ContextFormatJson = "json"
+	ContextFormatYaml = "yaml"
+)
+
+var ValidContextFormats = []string{ContextFormatJson, ContextFormatYaml}
+
+const hiddenValue = "***"
+
+// getContextScope returns scope by its name or by name of output
+func getContextScope(config *Config, scopeOrOutput string) (string, error) {
+	if slices.Contains(validScopes, scopeOrOutput) {
+		return scopeOrOutput, nil
+	}
+
+	for _, output := range config.Outputs {
+		if output.Name == scopeOrOutput {
+			return output.Scope, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s is neither scope (%s) nor output name", scopeOrOutput, strings.Join(validScopes, ", "))
+}
+
+// GetTemplateData returns template data of scope (or output) for item, formatted as json or yaml
+func GetTemplateData(config *Config, routines []Routine, scopeOrOutput string, itemName string, format string) (string, error) {
+	scope, err := getContextScope(config, scopeOrOutput)
+	if err != nil {
+		return "", err
+	}
+
+	// data contains configuration, it should not leak password
+	contextConfig := *config
+	if contextConfig.ConnectionString != "" {
+		contextConfig.ConnectionString = hiddenValue
+	}
+
+	templateData, err := getItemTemplateData(OutputConfig{Scope: scope}, routines, itemName, &contextConfig)
+	if err != nil {
+		return "", err
+	}
+
+	return formatContext(templateData, format)
+}
+
+// GetTemplateDataSchema returns JSON Schema of template data of scope (or output)
+func GetTemplateDataSchema(config *Config, scopeOrOutput string, format string) (string, error) {
+	scope, err := getContextScope(config, scopeOrOutput)
+	if err != nil {
+		return "", err
+	}
+
+	dataType, _ := getScopeDataTypes(scope)
+	return formatContext(getJsonSchema(dataType), format)
+}
+
+func formatContext(value interface{}, format string) (string, error) {
+	jsonValue, err := toPrettyJson(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case ContextFormatJson:
+		return jsonValue + "\n", nil
+	case ContextFormatYaml:
+		return jsonToYaml(jsonValue)
+	default:
+		return "", fmt.Errorf("invalid format '%s', valid formats are %s", format, strings.Join(ValidContextFormats, ", "))
+	}
+}
+
+// jsonToYaml converts through json, so field names and their order are the same in both formats
+func jsonToYaml(jsonValue string) (string, error) {
+	var document yaml.Node
+
+	// json is valid yaml, only styles have to be reset to block style
+	err := yaml.Unmarshal([]byte(jsonValue), &document)
+	if err != nil {
+		return "", fmt.Errorf("converting to yaml: %s", err)
+	}
+	resetYamlStyle(&document)
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+
+	err = encoder.Encode(&document)
+	if err != nil {
+		return "", fmt.Errorf("converting to yaml: %s", err)
+	}
+
+	return out.String(), nil
+}
+
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
+
+var nullBoolType = reflect.TypeOf(null.Bool{})
+
+// getJsonSchema describes how type is serialized by encoding/json, structs are in $defs
+func getJsonSchema(dataType reflect.Type) map[string]interface{} {
+	definitions := make(map[string]interface{})
+
+	// template data is never nil
+	schema := getTypeSchema(indirectType(dataType), definitions)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$defs"] = definitions
+
+	return schema
+}
+
+func getTypeSchema(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	if t == nullBoolType {
+		return map[string]interface{}{"type": []string{"boolean", "null"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]interface{}{"anyOf": []interface{}{getTypeSchema(t.Elem(), definitions), map[string]interface{}{"type": "null"}}}
+	case reflect.Struct:
+		name := t.Name()
+		if _, exists := definitions[name]; !exists {
+			// placeholder, so recursive types don't loop
+			definitions[name] = nil
+			definitions[name] = getStructSchema(t, definitions)
+		}
+
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": getTypeSchema(t.Elem(), definitions)}
+	case reflect.Map:
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": getTypeSchema(t.Elem(), definitions)}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// interface{} can be anything
+		return map[string]interface{}{}
+	}
+}
+
+func getStructSchema(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, hasTag := field.Tag.Lookup("json"); hasTag {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		properties[name] = getTypeSchema(field.Type, definitions)
+		required = append(required, name)
+	}
+
+	return map[string]interface{}{
+		"title":                t.Name(),
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
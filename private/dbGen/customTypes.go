@@ -0,0 +1,153 @@
+package dbGen
+
+import (
+	"encoding/json"
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+)
+
+// Enums and composite types used by routines, they can be generated with enum and customType outputs
+
+type Enum struct {
+	Name       string
+	Schema     string
+	DbTypeName string
+	Values     []string
+}
+
+type CustomType struct {
+	Name       string
+	Schema     string
+	DbTypeName string
+	Properties []Property
+}
+
+// StringList is loaded from database as json array
+type StringList []string
+
+func (list *StringList) Scan(src interface{}) error {
+	var content []byte
+
+	switch value := src.(type) {
+	case nil:
+		*list = nil
+		return nil
+	case string:
+		content = []byte(value)
+	case []byte:
+		content = value
+	default:
+		return fmt.Errorf("cannot scan %T to string list", src)
+	}
+
+	if len(content) == 0 {
+		*list = nil
+		return nil
+	}
+
+	return json.Unmarshal(content, list)
+}
+
+// enumValuesColumn returns select column enum_values with json array of labels for type identified by schema and name columns
+func enumValuesColumn(schemaColumn string, nameColumn string) string {
+	return fmt.Sprintf(`coalesce((select json_agg(e.enumlabel order by e.enumsortorder)::text
+			          from pg_enum e
+			          join pg_type t on t.oid = e.enumtypid
+			          join pg_namespace n on n.oid = t.typnamespace
+			          where n.nspname = %[1]s and t.typname = %[2]s), '') as enum_values`, schemaColumn, nameColumn)
+}
+
+// getTypeName creates name same way as function names are created
+func getTypeName(schema string, dbTypeName string) string {
+	return getFunctionName(dbTypeName, schema, "")
+}
+
+// getRoutineEnums returns distinct enums used by parameters and columns of routine
+func getRoutineEnums(routine DbRoutine) []Enum {
+	enums := make([]Enum, 0)
+	added := make(map[string]bool)
+
+	parameters := append(append([]DbParameter{}, routine.InParameters...), routine.OutParameters...)
+	if len(routine.EnumValues) > 0 {
+		parameters = append(parameters, DbParameter{UDTSchema: routine.UdtTypeScheme, UDTName: routine.UdtTypeName, EnumValues: routine.EnumValues})
+	}
+
+	for _, parameter := range parameters {
+		key := parameter.UDTSchema + "." + parameter.UDTName
+		if len(parameter.EnumValues) == 0 || added[key] {
+			continue
+		}
+		added[key] = true
+
+		enums = append(enums, Enum{
+			Name:       getTypeName(parameter.UDTSchema, parameter.UDTName),
+			Schema:     parameter.UDTSchema,
+			DbTypeName: parameter.UDTName,
+			Values:     parameter.EnumValues,
+		})
+	}
+
+	return enums
+}
+
+// getRoutineCustomType returns composite type returned by routine, columns are mapped without routine specific overrides
+func getRoutineCustomType(routine DbRoutine, globalTypeMappings *map[string]mapping, config *Config) (*CustomType, []JsonModel, error) {
+	if routine.DataType != userDefinedType || routine.RangeSubtype != "" || len(routine.EnumValues) > 0 || len(routine.OutParameters) == 0 {
+		return nil, nil, nil
+	}
+
+	name := getTypeName(routine.UdtTypeScheme, routine.UdtTypeName)
+
+	properties, jsonModels, err := mapModel(routine, name, globalTypeMappings, &emptyMapping, config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mapping custom type %s: %s", routine.UdtTypeName, err)
+	}
+
+	return &CustomType{
+		Name:       name,
+		Schema:     routine.UdtTypeScheme,
+		DbTypeName: routine.UdtTypeName,
+		Properties: properties,
+	}, jsonModels, nil
+}
+
+func collectEnums(routines []Routine) []Enum {
+	enums := make([]Enum, 0)
+	added := make(map[string]bool)
+
+	for _, routine := range routines {
+		for _, enum := range routine.Enums {
+			key := enum.Schema + "." + enum.DbTypeName
+			if added[key] {
+				continue
+			}
+			added[key] = true
+
+			enums = append(enums, enum)
+		}
+	}
+
+	return enums
+}
+
+func collectCustomTypes(routines []Routine) []CustomType {
+	customTypes := make([]CustomType, 0)
+	added := make(map[string]bool)
+
+	for _, routine := range routines {
+		if routine.CustomType == nil {
+			continue
+		}
+
+		key := routine.CustomType.Schema + "." + routine.CustomType.DbTypeName
+		if added[key] {
+			continue
+		}
+		added[key] = true
+
+		common2.LogDebug("Custom type %s returned by %s", key, routine.DbFullFunctionName)
+		customTypes = append(customTypes, *routine.CustomType)
+	}
+
+	return customTypes
+}
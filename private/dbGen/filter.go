@@ -33,13 +33,37 @@ func functionShouldBeGenerated(functionName string, schemaConfig *SchemaConfig)
 	// set explicitly
 	val, contains := schemaConfig.Functions[functionName]
 	if !contains {
-		return schemaConfig.AllFunctions
+		return patternShouldBeGenerated(functionName, schemaConfig)
 	}
 
 	helpers.LogDebug("Function %s has generation explicitly set to %t", functionName, val.Generate)
 	return val.Generate
 }
 
+// patternShouldBeGenerated uses matching pattern entries that set Generate, routine is excluded if any of them is set to false.
+// Patterns setting only Tags and Extra don't change generation
+func patternShouldBeGenerated(functionName string, schemaConfig *SchemaConfig) bool {
+	generatePatternSet := false
+
+	for _, patternMapping := range getPatternMappings(functionName, schemaConfig) {
+		if !patternMapping.generateIsSet {
+			continue
+		}
+		generatePatternSet = true
+
+		if !patternMapping.Generate {
+			helpers.LogDebug("Function %s is excluded by pattern", functionName)
+			return false
+		}
+	}
+
+	if !generatePatternSet {
+		return schemaConfig.AllFunctions
+	}
+
+	return true
+}
+
 func getSchemaConfigMap(config *Config) map[string]SchemaConfig {
 	schemaMap := make(map[string]SchemaConfig)
 
@@ -0,0 +1,206 @@
+package dbGen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// getFilterTestSchema decodes Functions the same way as configuration, viper passes keys lowercased
+func getFilterTestSchema(t *testing.T, allFunctions bool, functions map[string]interface{}) SchemaConfig {
+	t.Helper()
+
+	decoded, err := decodeFunctionMap(functions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return SchemaConfig{Schema: "public", AllFunctions: allFunctions, Functions: decoded.(map[string]RoutineMapping)}
+}
+
+func TestFunctionShouldBeGenerated(t *testing.T) {
+	tests := []struct {
+		name         string
+		allFunctions bool
+		functions    map[string]interface{}
+		want         []string
+	}{
+		{
+			name:         "all functions",
+			allFunctions: true,
+			want:         []string{"admin_get", "admin_set", "get_users"},
+		},
+		{
+			name: "explicit entries",
+			functions: map[string]interface{}{
+				"get_users": true,
+				"admin_get": map[string]interface{}{"mappedname": "AdminGet"},
+				"admin_set": false,
+			},
+			want: []string{"admin_get", "get_users"},
+		},
+		{
+			name:         "pattern excludes",
+			allFunctions: true,
+			functions:    map[string]interface{}{"admin_*": false},
+			want:         []string{"get_users"},
+		},
+		{
+			name:         "pattern excludes with generate key",
+			allFunctions: true,
+			functions:    map[string]interface{}{"admin_*": map[string]interface{}{"generate": false, "tags": []interface{}{"admin"}}},
+			want:         []string{"get_users"},
+		},
+		{
+			name:      "pattern includes",
+			functions: map[string]interface{}{"admin_*": true},
+			want:      []string{"admin_get", "admin_set"},
+		},
+		{
+			name:      "pattern includes with generate key",
+			functions: map[string]interface{}{"admin_*": map[string]interface{}{"Generate": true}},
+			want:      []string{"admin_get", "admin_set"},
+		},
+		// pattern entries setting only Tags and Extra attach metadata, AllFunctions decides
+		{
+			name:      "tags only pattern doesn't include",
+			functions: map[string]interface{}{"admin_*": map[string]interface{}{"tags": []interface{}{"admin"}}},
+			want:      []string{},
+		},
+		{
+			name:         "tags only pattern doesn't exclude",
+			allFunctions: true,
+			functions: map[string]interface{}{
+				"admin_*": map[string]interface{}{"tags": []interface{}{"admin"}, "extra": map[string]interface{}{"area": "admin"}},
+				"*":       map[string]interface{}{"tags": []interface{}{"all"}},
+			},
+			want: []string{"admin_get", "admin_set", "get_users"},
+		},
+		{
+			name:         "any excluding pattern wins",
+			allFunctions: true,
+			functions: map[string]interface{}{
+				"admin_*": true,
+				"*_set":   false,
+				"*":       map[string]interface{}{"tags": []interface{}{"all"}},
+			},
+			want: []string{"admin_get", "get_users"},
+		},
+		{
+			name: "explicit entry wins over pattern",
+			functions: map[string]interface{}{
+				"admin_*":   false,
+				"admin_get": map[string]interface{}{"tags": []interface{}{"admin"}},
+				"get_*":     true,
+			},
+			want: []string{"admin_get", "get_users"},
+		},
+		{
+			name:         "explicit entry excludes",
+			allFunctions: true,
+			functions: map[string]interface{}{
+				"admin_*":   true,
+				"admin_set": map[string]interface{}{"generate": false},
+			},
+			want: []string{"admin_get", "get_users"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routines := []DbRoutine{
+				{RoutineSchema: "public", RoutineName: "admin_get"},
+				{RoutineSchema: "public", RoutineName: "admin_set"},
+				{RoutineSchema: "public", RoutineName: "get_users"},
+				{RoutineSchema: "other", RoutineName: "get_users"},
+			}
+
+			schemaConfig := getFilterTestSchema(t, test.allFunctions, test.functions)
+			config := &Config{Generate: []SchemaConfig{schemaConfig}}
+
+			filtered, err := FilterFunctions(&routines, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0)
+			for _, routine := range filtered {
+				if routine.RoutineSchema != "public" {
+					t.Errorf("routine of schema %s without schema config is generated", routine.RoutineSchema)
+				}
+				got = append(got, routine.RoutineName)
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPatternMetadata(t *testing.T) {
+	schemaConfig := getFilterTestSchema(t, true, map[string]interface{}{
+		"admin_*": map[string]interface{}{"tags": []interface{}{"admin", "api"}, "extra": map[string]interface{}{"area": "admin", "audit": true}},
+		"*_get":   map[string]interface{}{"generate": true, "tags": []interface{}{"read"}},
+		"*":       false,
+		"admin_get": map[string]interface{}{
+			"tags":  []interface{}{"api", "custom"},
+			"extra": map[string]interface{}{"area": "custom"},
+		},
+	})
+	schemaConfigs := map[string]SchemaConfig{"public": schemaConfig}
+
+	// metadata of all matching patterns is applied, including patterns that don't set Generate,
+	// explicit entry is applied last
+	mapping := getRoutineMapping(DbRoutine{RoutineSchema: "public", RoutineName: "admin_get", RoutineNameWithParams: "admin_get()"}, schemaConfigs)
+	if want := []string{"read", "admin", "api", "custom"}; !slices.Equal(mapping.Tags, want) {
+		t.Errorf("got tags %v, want %v", mapping.Tags, want)
+	}
+	if mapping.Extra["area"] != "custom" || mapping.Extra["audit"] != true {
+		t.Errorf("got extra %v", mapping.Extra)
+	}
+
+	// routine without entry gets metadata of patterns only
+	mapping = getRoutineMapping(DbRoutine{RoutineSchema: "public", RoutineName: "admin_set"}, schemaConfigs)
+	if want := []string{"admin", "api"}; !slices.Equal(mapping.Tags, want) {
+		t.Errorf("got tags %v, want %v", mapping.Tags, want)
+	}
+	if mapping.Extra["area"] != "admin" {
+		t.Errorf("got extra %v", mapping.Extra)
+	}
+
+	// "*" excludes admin_set even though admin_* only sets tags
+	if functionShouldBeGenerated("admin_set", &schemaConfig) {
+		t.Error("admin_set is generated")
+	}
+	if !functionShouldBeGenerated("admin_get", &schemaConfig) {
+		t.Error("admin_get with explicit entry is not generated")
+	}
+}
+
+func TestInvalidPatternEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping interface{}
+		wantErr string
+	}{
+		{"mapped name", map[string]interface{}{"mappedname": "Admin"}, "can only set Generate, Tags and Extra"},
+		{"model", map[string]interface{}{"model": map[string]interface{}{"id": false}}, "can only set Generate, Tags and Extra"},
+		{"parameters", map[string]interface{}{"parameters": map[string]interface{}{"id": map[string]interface{}{"mappedname": "Id"}}}, "can only set Generate, Tags and Extra"},
+		{"dont retrieve values", map[string]interface{}{"dontretrievevalues": true}, "can only set Generate, Tags and Extra"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decodeFunctionMap(map[string]interface{}{"admin_*": test.mapping})
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v, want %s", err, test.wantErr)
+			}
+		})
+	}
+
+	_, err := decodeFunctionMap(map[string]interface{}{"admin_[": true})
+	if err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("got error %v, want invalid pattern", err)
+	}
+}
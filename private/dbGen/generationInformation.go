@@ -21,6 +21,7 @@ type GenerationInformation struct {
 	Version  string      `json:"version"`
 	Time     time.Time   `json:"time"`
 	Routines []DbRoutine `json:"routines"`
+	Files    []string    `json:"files"` // generated files relative to output folder
 }
 
 type databaseChanges struct {
@@ -52,7 +53,7 @@ func LoadGenerationInformation(config *Config) (*GenerationInformation, bool) {
 }
 
 // SaveGenerationInformation Saves json with generation info inside output folder.
-func SaveGenerationInformation(config *Config, routines []DbRoutine, version string) error {
+func SaveGenerationInformation(config *Config, routines []DbRoutine, files []string, version string) error {
 	// make local copy because we will be removing specific name
 	routinesCopy := make([]DbRoutine, len(routines))
 	copy(routinesCopy, routines)
@@ -65,6 +66,7 @@ func SaveGenerationInformation(config *Config, routines []DbRoutine, version str
 		Version:  version,
 		Time:     time.Now(),
 		Routines: routinesCopy,
+		Files:    files,
 	}
 
 	path := filepath.Join(config.OutputFolder, generationInfoFileName)
@@ -1,15 +1,27 @@
 package dbGen
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"github.com/keenmate/db-gen/private/helpers"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"text/template"
 )
 
+// All template functions are pure, they depend only on arguments, so generated code is reproducible.
+// Functions taking list or string have it as the last argument, so they can be used in pipelines: {{.Functions | filter "HasReturn" | len}}
+
+// maxRepeatCount limits repeat and indent, so template value like {{repeat 1000000000 "x"}} can't exhaust memory
+const maxRepeatCount = 10000
+
 func getTemplateFunctions() template.FuncMap {
 	return template.FuncMap{
-		"inc": func(n int) int {
-			return n + 1
-		},
+		// case
 		"pascalCased": func(s string) string {
 			return helpers.ToPascalCase(s)
 		},
@@ -19,5 +31,479 @@ func getTemplateFunctions() template.FuncMap {
 		"snakeCased": func(s string) string {
 			return helpers.ToSnakeCase(s)
 		},
+		"pluralize":   helpers.Pluralize,
+		"singularize": helpers.Singularize,
+
+		// strings
+		"replace": func(old string, new string, s string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+		"trimPrefix": func(prefix string, s string) string {
+			return strings.TrimPrefix(s, prefix)
+		},
+		"trimSuffix": func(suffix string, s string) string {
+			return strings.TrimSuffix(s, suffix)
+		},
+		"hasPrefix": func(prefix string, s string) bool {
+			return strings.HasPrefix(s, prefix)
+		},
+		"hasSuffix": func(suffix string, s string) bool {
+			return strings.HasSuffix(s, suffix)
+		},
+		"contains": func(substring string, s string) bool {
+			return strings.Contains(s, substring)
+		},
+		"split": func(separator string, s string) []string {
+			return strings.Split(s, separator)
+		},
+		"repeat":  repeat,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"indent":  indent,
+		"nindent": nindent,
+		"quote":   strconv.Quote,
+
+		// sql
+		"quoteIdent":   quoteIdent,
+		"quoteLiteral": quoteLiteral,
+
+		// collections
+		"list":   list,
+		"dict":   dict,
+		"first":  first,
+		"last":   last,
+		"join":   join,
+		"sortBy": sortBy,
+		"filter": filter,
+		"uniq":   uniq,
+
+		// metadata
+		"hasTag":  hasTag,
+		"withTag": withTag,
+
+		// logic
+		"default":  defaultValue,
+		"ternary":  ternary,
+		"coalesce": coalesce,
+		"empty":    isEmpty,
+
+		// arithmetic
+		"inc": func(n int) int {
+			return n + 1
+		},
+		"dec": func(n int) int {
+			return n - 1
+		},
+		"add": func(a int, b int) int {
+			return a + b
+		},
+		"sub": func(a int, b int) int {
+			return a - b
+		},
+		"mul": func(a int, b int) int {
+			return a * b
+		},
+		"div": divide,
+		"mod": modulo,
+		"max": func(a int, b int) int {
+			return max(a, b)
+		},
+		"min": func(a int, b int) int {
+			return min(a, b)
+		},
+
+		// serialization
+		"toJson":       toJson,
+		"toPrettyJson": toPrettyJson,
+	}
+}
+
+func repeat(count int, s string) (string, error) {
+	if count > maxRepeatCount {
+		return "", fmt.Errorf("repeat count %d is over limit %d", count, maxRepeatCount)
+	}
+
+	return strings.Repeat(s, max(count, 0)), nil
+}
+
+// indent prefixes every non-empty line with spaces
+func indent(spaces int, s string) (string, error) {
+	if spaces > maxRepeatCount {
+		return "", fmt.Errorf("indent %d is over limit %d", spaces, maxRepeatCount)
+	}
+
+	padding := strings.Repeat(" ", max(spaces, 0))
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = padding + line
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// nindent is indent starting with new line, so {{- include "x" . | nindent 4}} can be placed at the end of line
+func nindent(spaces int, s string) (string, error) {
+	indented, err := indent(spaces, s)
+	if err != nil {
+		return "", err
+	}
+
+	return "\n" + indented, nil
+}
+
+func list(items ...interface{}) []interface{} {
+	return items
+}
+
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires key and value pairs")
+	}
+
+	result := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key %v is not string", pairs[i])
+		}
+
+		result[key] = pairs[i+1]
+	}
+
+	return result, nil
+}
+
+func first(items interface{}) (interface{}, error) {
+	values, err := toList(items)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+
+	return values[0], nil
+}
+
+func last(items interface{}) (interface{}, error) {
+	values, err := toList(items)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+
+	return values[len(values)-1], nil
+}
+
+func join(separator string, items interface{}) (string, error) {
+	values, err := toList(items)
+	if err != nil {
+		return "", err
+	}
+
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprint(value)
+	}
+
+	return strings.Join(parts, separator), nil
+}
+
+// sortBy sorts items by field (or map key), nested fields are separated by dot, order of equal items is kept
+func sortBy(field string, items interface{}) ([]interface{}, error) {
+	values, err := toList(items)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]interface{}, len(values))
+	for i, value := range values {
+		keys[i], err = getFieldValue(value, field)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	indexes := make([]int, len(values))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return compareValues(keys[indexes[i]], keys[indexes[j]]) < 0
+	})
+
+	sorted := make([]interface{}, len(values))
+	for i, index := range indexes {
+		sorted[i] = values[index]
+	}
+
+	return sorted, nil
+}
+
+// filter keeps items with true field ({{filter "HasReturn" .Functions}}) or field equal to value ({{filter "Schema" "public" .Functions}})
+func filter(field string, args ...interface{}) ([]interface{}, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("filter requires field, optional value and list")
+	}
+
+	values, err := toList(args[len(args)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0)
+	for _, value := range values {
+		fieldValue, err := getFieldValue(value, field)
+		if err != nil {
+			return nil, err
+		}
+
+		keep := !isEmpty(fieldValue)
+		if len(args) == 2 {
+			keep = compareValues(fieldValue, args[0]) == 0
+		}
+
+		if keep {
+			result = append(result, value)
+		}
+	}
+
+	return result, nil
+}
+
+func uniq(items interface{}) ([]interface{}, error) {
+	values, err := toList(items)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		duplicate := false
+		for _, existing := range result {
+			if reflect.DeepEqual(existing, value) {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			result = append(result, value)
+		}
+	}
+
+	return result, nil
+}
+
+// hasTag checks tags of routine or property ({{if hasTag "cacheable" $routine}}), list of tags can be used as well
+func hasTag(tag string, item interface{}) (bool, error) {
+	tags, isList := item.([]string)
+	if !isList {
+		value, err := getFieldValue(item, "Tags")
+		if err != nil {
+			return false, err
+		}
+
+		tags, _ = value.([]string)
+	}
+
+	return slices.Contains(tags, tag), nil
+}
+
+// withTag keeps routines or properties having tag: {{range withTag "admin-only" .Functions}}
+func withTag(tag string, items interface{}) ([]interface{}, error) {
+	values, err := toList(items)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0)
+	for _, value := range values {
+		tagged, err := hasTag(tag, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if tagged {
+			result = append(result, value)
+		}
+	}
+
+	return result, nil
+}
+
+// defaultValue returns value, or default when value is empty: {{.MappedName | default "x"}}
+func defaultValue(fallback interface{}, value interface{}) interface{} {
+	if isEmpty(value) {
+		return fallback
+	}
+
+	return value
+}
+
+func ternary(trueValue interface{}, falseValue interface{}, condition bool) interface{} {
+	if condition {
+		return trueValue
+	}
+
+	return falseValue
+}
+
+// coalesce returns first value that is not empty
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !isEmpty(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// isEmpty uses the same rules as {{if}}, so zero values, nil and empty collections are empty
+func isEmpty(value interface{}) bool {
+	truth, _ := template.IsTrue(value)
+	return !truth
+}
+
+func divide(a int, b int) (int, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+
+	return a / b, nil
+}
+
+func modulo(a int, b int) (int, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+
+	return a % b, nil
+}
+
+func toJson(value interface{}) (string, error) {
+	return marshalJson(value, "")
+}
+
+func toPrettyJson(value interface{}) (string, error) {
+	return marshalJson(value, "  ")
+}
+
+// marshalJson doesn't escape html characters, map keys are sorted by encoding/json
+func marshalJson(value interface{}, indent string) (string, error) {
+	var out bytes.Buffer
+
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	err := encoder.Encode(value)
+	if err != nil {
+		return "", fmt.Errorf("converting to json: %s", err)
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// toList converts any slice or array to list, nil is empty list
+func toList(items interface{}) ([]interface{}, error) {
+	if items == nil {
+		return []interface{}{}, nil
+	}
+
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected list, got %T", items)
+	}
+
+	result := make([]interface{}, value.Len())
+	for i := range result {
+		result[i] = value.Index(i).Interface()
+	}
+
+	return result, nil
+}
+
+// getFieldValue returns struct field or map value, path can contain nested fields separated by dot
+func getFieldValue(item interface{}, path string) (interface{}, error) {
+	value := reflect.ValueOf(item)
+
+	for _, field := range strings.Split(path, ".") {
+		for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return nil, nil
+			}
+			value = value.Elem()
+		}
+
+		switch value.Kind() {
+		case reflect.Struct:
+			fieldValue := value.FieldByName(field)
+			if !fieldValue.IsValid() || !fieldValue.CanInterface() {
+				return nil, fmt.Errorf("%s has no field %s", value.Type(), field)
+			}
+			value = fieldValue
+		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return nil, fmt.Errorf("cannot get %s from %s", field, value.Type())
+			}
+
+			value = value.MapIndex(reflect.ValueOf(field).Convert(value.Type().Key()))
+			if !value.IsValid() {
+				return nil, nil
+			}
+		default:
+			return nil, fmt.Errorf("cannot get %s from %s", field, value.Type())
+		}
+	}
+
+	return value.Interface(), nil
+}
+
+// compareValues compares numbers by value, strings and booleans naturally and everything else by its text
+func compareValues(a interface{}, b interface{}) int {
+	aNumber, aIsNumber := toFloat(a)
+	bNumber, bIsNumber := toFloat(b)
+	if aIsNumber && bIsNumber {
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	aBool, aIsBool := a.(bool)
+	bBool, bIsBool := b.(bool)
+	if aIsBool && bIsBool {
+		switch {
+		case aBool == bBool:
+			return 0
+		case !aBool:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	reflected := reflect.ValueOf(value)
+
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflected.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float(), true
+	default:
+		return 0, false
 	}
 }
@@ -0,0 +1,263 @@
+package dbGen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type functionsTestItem struct {
+	Name     string
+	Order    int
+	Enabled  bool
+	Nested   *functionsTestItem
+	Values   map[string]interface{}
+	internal string
+}
+
+func TestGetFieldValue(t *testing.T) {
+	item := functionsTestItem{
+		Name:   "a",
+		Order:  2,
+		Nested: &functionsTestItem{Name: "nested"},
+		Values: map[string]interface{}{"key": "value", "map": map[string]interface{}{"inner": 1}},
+	}
+
+	tests := []struct {
+		item    interface{}
+		path    string
+		want    interface{}
+		wantErr bool
+	}{
+		{item: item, path: "Name", want: "a"},
+		{item: &item, path: "Order", want: 2},
+		{item: item, path: "Nested.Name", want: "nested"},
+		{item: item, path: "Values.key", want: "value"},
+		{item: item, path: "Values.map.inner", want: 1},
+		{item: map[string]string{"a": "b"}, path: "a", want: "b"},
+		// missing map key and nil pointer are nil, so items without value can be sorted and filtered
+		{item: item, path: "Values.missing", want: nil},
+		{item: functionsTestItem{}, path: "Nested.Name", want: nil},
+		{item: item, path: "Missing", wantErr: true},
+		{item: item, path: "internal", wantErr: true},
+		{item: item, path: "Name.Length", wantErr: true},
+		{item: map[int]string{1: "a"}, path: "1", wantErr: true},
+		{item: "text", path: "Name", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, err := getFieldValue(test.item, test.path)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a    interface{}
+		b    interface{}
+		want int
+	}{
+		{1, 2, -1},
+		{2, 1, 1},
+		{2, 2, 0},
+		// numbers of different types are compared by value
+		{int64(10), 9.5, 1},
+		{uint8(3), 3, 0},
+		{-1, uint(1), -1},
+		// numbers aren't compared as text
+		{10, 9, 1},
+		{false, true, -1},
+		{true, false, 1},
+		{true, true, 0},
+		{"a", "b", -1},
+		{"B", "a", -1},
+		{"a", "a", 0},
+		// other values are compared by text
+		{"10", "9", -1},
+		{nil, "a", -1},
+		{true, "true", 0},
+		{1, "1", 0},
+	}
+
+	for _, test := range tests {
+		if got := compareValues(test.a, test.b); got != test.want {
+			t.Errorf("compareValues(%#v, %#v) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	items := []functionsTestItem{
+		{Name: "c", Order: 10, Nested: &functionsTestItem{Name: "y"}},
+		{Name: "a", Order: 9, Values: map[string]interface{}{"rank": 2}},
+		{Name: "b", Order: 10, Nested: &functionsTestItem{Name: "x"}, Values: map[string]interface{}{"rank": 1}},
+		{Name: "d", Order: 1},
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Name", "abcd"},
+		// equal items keep order
+		{"Order", "dacb"},
+		{"Nested.Name", "adbc"},
+		// missing values are compared as text "<nil>"
+		{"Values.rank", "bacd"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.field, func(t *testing.T) {
+			sorted, err := sortBy(test.field, items)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := functionsTestNames(sorted); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	sorted, err := sortBy("Name", []*functionsTestItem{{Name: "b"}, {Name: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := functionsTestNames(sorted); got != "ab" {
+		t.Errorf("got %s, want ab", got)
+	}
+
+	sorted, err = sortBy("Name", nil)
+	if err != nil || len(sorted) != 0 {
+		t.Errorf("got %v, %v, want empty list", sorted, err)
+	}
+
+	_, err = sortBy("Missing", items)
+	if err == nil {
+		t.Error("sorting by missing field didn't fail")
+	}
+
+	_, err = sortBy("Name", "abc")
+	if err == nil {
+		t.Error("sorting string didn't fail")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := []functionsTestItem{
+		{Name: "a", Order: 1, Enabled: true},
+		{Name: "b", Order: 0, Values: map[string]interface{}{"schema": "public"}},
+		{Name: "c", Order: 2, Enabled: true, Nested: &functionsTestItem{Name: "x"}, Values: map[string]interface{}{"schema": "admin"}},
+	}
+
+	tests := []struct {
+		field string
+		args  []interface{}
+		want  string
+	}{
+		{"Enabled", nil, "ac"},
+		{"Order", nil, "ac"},
+		{"Nested", nil, "c"},
+		{"Values.schema", nil, "bc"},
+		{"Enabled", []interface{}{false}, "b"},
+		{"Order", []interface{}{2}, "c"},
+		{"Order", []interface{}{int64(1)}, "a"},
+		{"Name", []interface{}{"b"}, "b"},
+		{"Values.schema", []interface{}{"admin"}, "c"},
+		{"Nested.Name", []interface{}{"x"}, "c"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.field, test.args), func(t *testing.T) {
+			filtered, err := filter(test.field, append(test.args, items)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := functionsTestNames(filtered); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	_, err := filter("Name", items, "a", "b")
+	if err == nil {
+		t.Error("filter with too many arguments didn't fail")
+	}
+
+	_, err = filter("Missing", items)
+	if err == nil {
+		t.Error("filter by missing field didn't fail")
+	}
+}
+
+func TestRepeatLimit(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{template: `{{repeat 3 "ab"}}`, want: "ababab"},
+		{template: `{{repeat -1 "ab"}}`, want: ""},
+		{template: `{{repeat 10000 "x" | len}}`, want: "10000"},
+		{template: `{{repeat 1000000000 "x"}}`, wantErr: true},
+		{template: `{{indent 2 "a\n\nb"}}`, want: "  a\n\n  b"},
+		{template: `{{nindent 2 "a"}}`, want: "\n  a"},
+		{template: `{{indent 1000000000 "a"}}`, wantErr: true},
+		{template: `{{nindent 1000000000 "a"}}`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			tmpl, err := parseInlineTemplate("test", test.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out strings.Builder
+			err = tmpl.Execute(&out, nil)
+			if test.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "over limit") {
+					t.Errorf("got error %v, want limit error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if out.String() != test.want {
+				t.Errorf("got %q, want %q", out.String(), test.want)
+			}
+		})
+	}
+}
+
+func functionsTestNames(items []interface{}) string {
+	var names strings.Builder
+	for _, item := range items {
+		switch value := item.(type) {
+		case functionsTestItem:
+			names.WriteString(value.Name)
+		case *functionsTestItem:
+			names.WriteString(value.Name)
+		}
+	}
+
+	return names.String()
+}
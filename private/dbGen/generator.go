@@ -1,296 +1,262 @@
 package dbGen
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"bytes"
+	"errors"
 	"fmt"
 	common2 "github.com/keenmate/db-gen/private/helpers"
 	"github.com/keenmate/db-gen/private/version"
-	"io"
+	"io/fs"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"text/template"
 )
 
 var ValidCaseNormalized = []string{"snakecase", "camelcase", "pascalcase"}
 
-func Generate(routines []Routine, config *Config) error {
-	fileHashes, err := generateFileHashes(config.OutputFolder)
-	if err != nil {
-		return fmt.Errorf("generating file hashes: %s", err)
-	}
-	common2.LogDebug("Got %d file hashes", len(*fileHashes))
+// generatedFile is rendered output file, files are rendered in memory before anything is written
+type generatedFile struct {
+	path    string // relative to output folder
+	output  string // name of output that generated the file
+	content []byte
+}
 
-	log.Printf("Ensuring output folder...")
+type renderedItem struct {
+	file          *generatedFile // nil when item is filtered out
+	debugMessages []string
+	failed        bool
+}
 
-	err = ensureOutputFolder(config)
+// Generate writes files of all outputs and deletes files of previous generation that are not generated anymore,
+// returns paths of generated files relative to output folder
+func Generate(routines []Routine, config *Config, previousInfo *GenerationInformation) ([]string, error) {
+	// nothing is written until all templates are rendered, so failing template doesn't leave output half-generated
+	files, err := renderOutputs(routines, version.GetBuildInfo(), config)
 	if err != nil {
-		return fmt.Errorf("ensuring output folder: %s", err)
+		return nil, err
 	}
 
-	log.Printf("Generating dbcontext...")
-
-	err = generateDbContext(routines, fileHashes, config)
+	err = preserveRegions(config, files)
 	if err != nil {
-		return fmt.Errorf("generating dbcontext: %s", err)
-
+		return nil, err
 	}
 
-	if config.GenerateModels {
-		log.Printf("Generating models...")
-
-		err = generateModels(routines, fileHashes, config)
-		if err != nil {
-			return fmt.Errorf("generating models: %s", err)
+	log.Printf("Staging changed files...")
 
-		}
-	} else {
-		log.Printf("Skipping generating models")
+	transaction, err := newOutputTransaction(config.OutputFolder)
+	if err != nil {
+		return nil, err
 	}
+	defer transaction.close()
 
-	if config.GenerateProcessors {
-		log.Printf("Generating processors...")
+	generatedPaths := make([]string, len(files))
+	for i, file := range files {
+		generatedPaths[i] = filepath.ToSlash(file.path)
 
-		err = generateProcessors(routines, fileHashes, config)
+		changed, err := transaction.stage(file)
 		if err != nil {
-			return fmt.Errorf("generating processors: %s", err)
+			return nil, fmt.Errorf("staging %s: %s", file.path, err)
+		}
 
+		if !changed {
+			common2.LogDebug("Same: %s", file.path)
 		}
-	} else {
-		log.Printf("Skipping generating processors")
 	}
-	return nil
-}
 
-func generateDbContext(routines []Routine, hashMap *map[string]string, config *Config) error {
-	dbContextTemplate, err := parseTemplate(config.DbContextTemplate)
+	deletedFiles, err := getDeletedFiles(config, previousInfo, generatedPaths)
 	if err != nil {
-		return fmt.Errorf("loading dbContext template: %s", err)
+		return nil, err
 	}
 
-	data := &DbContextData{
-		Config:    config,
-		Functions: routines,
-		BuildInfo: version.GetBuildInfo(),
-	}
+	for _, file := range deletedFiles {
+		content, err := os.ReadFile(filepath.Join(config.OutputFolder, filepath.FromSlash(file)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: %s", file, err)
+		}
 
-	filename := changeCase("DbContext"+config.GeneratedFileExtension, config.GeneratedFileCase)
-	fp := filepath.Join(config.OutputFolder, filename)
+		warnAboutDeletedRegions(file, content)
+		transaction.delete(file)
+	}
 
-	changed, err := generateFile(data, dbContextTemplate, fp, hashMap)
+	err = transaction.commit()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("applying generated files, output folder was restored: %s", err)
 	}
 
-	if changed {
-		log.Printf("Updated: Dbcontext")
-	} else {
-		common2.LogDebug("Same: Dbcontext")
+	for _, file := range transaction.staged {
+		log.Printf("Updated: %s", file)
 	}
 
-	return nil
+	for _, file := range deletedFiles {
+		log.Printf("Deleted: %s", file)
+	}
 
+	return generatedPaths, nil
 }
 
-func generateModels(routines []Routine, hashMap *map[string]string, config *Config) error {
-	moduleTemplate, err := parseTemplate(config.ModelTemplate)
+// renderOutputs renders files of all outputs, nothing is written
+func renderOutputs(routines []Routine, buildInfo *version.BuildInformation, config *Config) ([]generatedFile, error) {
+	partials, err := loadTemplatePartials(config)
 	if err != nil {
-		return fmt.Errorf("loading module template: %s", err)
+		return nil, fmt.Errorf("loading template partials: %s", err)
 	}
 
-	err = os.MkdirAll(filepath.Join(config.OutputFolder, config.ModelsFolderName), 0777)
+	// every file can be generated only once
+	generatedPaths := make(map[string]string)
+	files := make([]generatedFile, 0)
 
-	for _, routine := range routines {
-		if !routine.HasReturn {
-			continue
-		}
-
-		filename := changeCase(routine.ModelName+config.GeneratedFileExtension, config.GeneratedFileCase)
-		relPath := filepath.Join(config.ModelsFolderName, filename)
-		filePath := filepath.Join(config.OutputFolder, relPath)
+	for _, output := range config.Outputs {
+		log.Printf("Generating %s...", output.Name)
 
-		data := &ModelTemplateData{
-			Config:    config,
-			Routine:   routine,
-			BuildInfo: version.GetBuildInfo(),
-		}
-
-		changed, err := generateFile(data, moduleTemplate, filePath, hashMap)
+		outputFiles, err := renderOutput(output, routines, partials, buildInfo, config)
 		if err != nil {
-			return fmt.Errorf("generating models: %s", err)
+			return nil, fmt.Errorf("generating %s: %s", output.Name, err)
 		}
 
-		if changed {
-			log.Printf("Updated: %s", relPath)
-		} else {
-			common2.LogDebug("Same: %s", relPath)
+		for _, file := range outputFiles {
+			if previousOutput, exists := generatedPaths[file.path]; exists {
+				return nil, fmt.Errorf("generating %s: file %s is already generated by output %s", output.Name, file.path, previousOutput)
+			}
+			generatedPaths[file.path] = output.Name
 		}
+
+		files = append(files, outputFiles...)
 	}
 
-	return nil
+	return files, nil
 }
 
-func generateProcessors(routines []Routine, hashMap *map[string]string, config *Config) error {
-	processorTemplate, err := parseTemplate(config.ProcessorTemplate)
+func renderOutput(output OutputConfig, routines []Routine, partials []templatePartial, buildInfo *version.BuildInformation, config *Config) ([]generatedFile, error) {
+	override, err := loadTemplateOverride(output)
 	if err != nil {
-		return fmt.Errorf("loading processor template: %s", err)
+		return nil, err
 	}
 
-	err = os.MkdirAll(filepath.Join(config.OutputFolder, config.ProcessorsFolderName), 0777)
+	outputTemplate, err := parseTemplate(output.Template, override, partials)
 	if err != nil {
-		return fmt.Errorf("creating processor output folder: %s", err)
+		return nil, fmt.Errorf("loading template: %s", err)
 	}
 
-	for _, routine := range routines {
-		// if GenerateProcessorsForVoidReturns it processors for all void returns
-		if !config.GenerateProcessorsForVoidReturns && !routine.HasReturn {
-			common2.LogDebug("dont generate processor for %s", routine.DbFullFunctionName)
-			continue
-		}
-
-		filename := changeCase(routine.ProcessorName+config.GeneratedFileExtension, config.GeneratedFileCase)
-		relPath := filepath.Join(config.ProcessorsFolderName, filename)
-		filePath := filepath.Join(config.OutputFolder, relPath)
-
-		data := &ProcessorTemplateData{
-			Config:    config,
-			Routine:   routine,
-			BuildInfo: version.GetBuildInfo(),
-		}
+	pathTemplate, err := parseInlineTemplate("path", output.Path)
+	if err != nil {
+		return nil, err
+	}
 
-		changed, err := generateFile(data, processorTemplate, filePath, hashMap)
+	var filterTemplate *template.Template
+	if output.Filter != "" {
+		filterTemplate, err = parseInlineTemplate("filter", output.Filter)
 		if err != nil {
-			return fmt.Errorf("generating processor %s: %s", routine.ProcessorName, err)
-		}
-
-		if changed {
-			log.Printf("Updated: %s", relPath)
-		} else {
-			common2.LogDebug("Same: %s", relPath)
+			return nil, err
 		}
 	}
 
-	return nil
-}
-
-func parseTemplate(templatePath string) (*template.Template, error) {
-	if !common2.PathExists(templatePath) {
-		return nil, fmt.Errorf("template file %s does not exist", templatePath)
-
-	}
-
-	name := filepath.Base(templatePath)
-
-	tmpl, err := template.New(name).
-		Funcs(getTemplateFunctions()).
-		ParseFiles(templatePath)
-
+	items, err := getOutputItems(output, routines, buildInfo, config)
 	if err != nil {
 		return nil, err
 	}
-	return tmpl, nil
-}
 
-func generateFile(data interface{}, template *template.Template, fp string, hashMap *map[string]string) (bool, error) {
+	// items are rendered in parallel, files and debug messages keep order of items, so result is the same as in sequential run
+	renderedItems := make([]renderedItem, len(items))
+	err = common2.RunParallel(len(items), config.Jobs, func(i int) error {
+		file, err := renderOutputItem(output, items[i], filterTemplate, pathTemplate, outputTemplate, &renderedItems[i])
+		if err != nil {
+			renderedItems[i].failed = true
+			return err
+		}
 
-	fp = filepath.Clean(fp)
+		renderedItems[i].file = file
+		return nil
+	})
 
-	// we want to ignore error for now
-	oldHash, _ := (*hashMap)[fp]
+	// items after the failed one are not logged, sequential run wouldn't get to them
+	for _, item := range renderedItems {
+		for _, message := range item.debugMessages {
+			common2.LogDebug("%s", message)
+		}
 
-	f, err := os.Create(fp)
-	defer f.Close()
-	if err != nil {
-		return false, err
+		if item.failed {
+			break
+		}
 	}
 
-	err = template.Execute(f, data)
-
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	newHash, _ := fileMd5Sum(fp)
-
-	// only makes sense if we don't clean folder
-	// otherwise we would have to keep track of what files we generated and delete the rest
-	changed := newHash != oldHash
-
-	//DebugLog("%s -> %s", oldHash, newHash)
+	files := make([]generatedFile, 0, len(items))
+	for _, item := range renderedItems {
+		// filtered out
+		if item.file != nil {
+			files = append(files, *item.file)
+		}
+	}
 
-	return changed, nil
+	return files, nil
 }
 
-func ensureOutputFolder(config *Config) error {
-	if config.ClearOutputFolder && common2.PathExists(config.OutputFolder) {
-		common2.LogDebug("Deleting contents of output folder")
-
-		err := common2.RemoveContents(config.OutputFolder)
+// renderOutputItem renders file of one item, nil if it is filtered out. It runs in parallel, so it doesn't log,
+// messages are added to result and logged in order of items
+func renderOutputItem(output OutputConfig, item outputItem, filterTemplate *template.Template, pathTemplate *template.Template, outputTemplate *template.Template, result *renderedItem) (*generatedFile, error) {
+	if filterTemplate != nil {
+		filterValue, err := renderInlineTemplate(filterTemplate, item.pathData)
 		if err != nil {
-			return fmt.Errorf("clearing output folder: %s", err)
+			return nil, err
 		}
-	}
 
-	err := os.MkdirAll(config.OutputFolder, 0777)
-	if err != nil {
-		return fmt.Errorf("creating output folder: %s", err)
+		if !isFilterTrue(filterValue) {
+			return nil, nil
+		}
 	}
 
-	return nil
-}
-
-func fileMd5Sum(filePath string) (string, error) {
-	file, err := os.Open(filePath)
+	relPath, err := getOutputPath(pathTemplate, item.pathData, output.FileCase)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer file.Close()
+	result.debugMessages = append(result.debugMessages, fmt.Sprintf("Rendered path %s", relPath))
 
-	hashFunc := md5.New()
-	if _, err := io.Copy(hashFunc, file); err != nil {
-		return "", err
+	var content bytes.Buffer
+	err = outputTemplate.Execute(&content, item.templateData)
+	if err != nil {
+		return nil, fmt.Errorf("rendering %s: %s", relPath, err)
 	}
 
-	hash := hex.EncodeToString(hashFunc.Sum(nil))
-	return hash, nil
+	return &generatedFile{path: relPath, output: output.Name, content: content.Bytes()}, nil
 }
 
-func generateFileHashes(outputFolder string) (*map[string]string, error) {
-	hashMap := make(map[string]string)
+// parseTemplate parses template with partials, override (can be nil) is parsed last and its blocks win
+func parseTemplate(templatePath string, override *templatePartial, partials []templatePartial) (*template.Template, error) {
+	if !isBuiltinPath(templatePath) && !common2.PathExists(templatePath) {
+		return nil, fmt.Errorf("template file %s does not exist", templatePath)
 
-	// If folder doesnt exist
-	if _, err := os.Stat(outputFolder); os.IsNotExist(err) {
-		return &hashMap, nil
 	}
 
-	err := filepath.Walk(outputFolder,
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-
-			if info.IsDir() {
-				return nil
-			}
+	content, err := readTemplateFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
 
-			path = filepath.Clean(path)
+	name := path.Base(filepath.ToSlash(templatePath))
 
-			hash, err := fileMd5Sum(path)
-			if err != nil {
-				return err
-			}
+	tmpl := template.New(name).Funcs(getTemplateFunctions())
+	tmpl.Funcs(template.FuncMap{"include": getIncludeFunction(tmpl)})
 
-			hashMap[path] = hash
-			return nil
-		})
+	err = addTemplatePartials(tmpl, partials)
+	if err != nil {
+		return nil, err
+	}
 
+	tmpl, err = tmpl.Parse(string(content))
 	if err != nil {
-		return nil, fmt.Errorf("generating hashes for output folder: %s", err)
+		return nil, err
 	}
 
-	return &hashMap, err
+	if override != nil {
+		return addTemplateOverride(tmpl, override)
+	}
 
+	return tmpl, nil
 }
 
 func changeCase(str string, desiredCase string) string {
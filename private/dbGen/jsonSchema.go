@@ -0,0 +1,363 @@
+package dbGen
+
+import (
+	"encoding/json"
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Json columns and parameters with json schema are mapped to classes generated from the schema.
+// Json schema types are mapped with Mappings using keys json:string, json:integer, json:number, json:boolean,
+// json:array, json:object and json:any. String format can be mapped specifically, e.g. json:string:date-time
+
+const jsonSchemaDirective = "@schema"
+
+const (
+	jsonMappingPrefix = "json:"
+	jsonObjectKey     = jsonMappingPrefix + "object"
+	jsonArrayKey      = jsonMappingPrefix + "array"
+	jsonAnyKey        = jsonMappingPrefix + "any"
+)
+
+type JsonModel struct {
+	Name        string
+	Description string
+	Properties  []JsonProperty
+}
+
+type JsonProperty struct {
+	JsonName     string
+	PropertyName string
+	PropertyType string
+	Description  string
+	Required     bool
+	Nullable     bool
+}
+
+type jsonSchemaConverter struct {
+	root           map[string]interface{}
+	models         []JsonModel
+	modelNames     map[string]bool
+	globalMappings *map[string]mapping
+	fallbackTypes  []string
+}
+
+// mapJsonSchema generates json models from schema and returns mapping of the root type and name of root model
+func mapJsonSchema(schema map[string]interface{}, name string, globalMappings *map[string]mapping) (*mapping, string, []JsonModel, error) {
+	converter := &jsonSchemaConverter{
+		root:           schema,
+		models:         make([]JsonModel, 0),
+		modelNames:     make(map[string]bool),
+		globalMappings: globalMappings,
+	}
+
+	typeMapping, rootModel, err := converter.resolveType(schema, name)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// fallback used anywhere in the schema is reported for the column
+	typeMapping.fallbackTypes = converter.fallbackTypes
+
+	return typeMapping, rootModel, converter.models, nil
+}
+
+// resolveType returns mapping for schema and name of model the type is based on
+func (converter *jsonSchemaConverter) resolveType(schema map[string]interface{}, name string) (*mapping, string, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		return converter.resolveRef(ref)
+	}
+
+	if title, ok := schema["title"].(string); ok && title != "" {
+		name = common2.ToPascalCase(title)
+	}
+
+	schemaType, _ := getJsonSchemaType(schema)
+
+	switch schemaType {
+	case "object":
+		properties, hasProperties := schema["properties"].(map[string]interface{})
+		if !hasProperties {
+			typeMapping, err := converter.getTypeMapping(jsonAnyKey)
+			return typeMapping, "", err
+		}
+
+		err := converter.addModel(schema, properties, name)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return converter.getObjectMapping(name), name, nil
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+			items = make(map[string]interface{})
+		}
+
+		itemMapping, itemModel, err := converter.resolveType(items, name+"Item")
+		if err != nil {
+			return nil, "", err
+		}
+
+		arrayMapping, err := converter.getTypeMapping(jsonArrayKey)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return applyTypePlaceholder(*arrayMapping, itemMapping.mappedType), itemModel, nil
+	case "":
+		typeMapping, err := converter.getTypeMapping(jsonAnyKey)
+		return typeMapping, "", err
+	default:
+		if format, ok := schema["format"].(string); ok {
+			formatMapping, exists := (*converter.globalMappings)[jsonMappingPrefix+schemaType+":"+format]
+			if exists {
+				return &formatMapping, "", nil
+			}
+		}
+
+		typeMapping, err := converter.getTypeMapping(jsonMappingPrefix + schemaType)
+		return typeMapping, "", err
+	}
+}
+
+func (converter *jsonSchemaConverter) resolveRef(ref string) (*mapping, string, error) {
+	path := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	if !strings.HasPrefix(ref, "#/") || len(path) != 2 {
+		return nil, "", fmt.Errorf("only local references to definitions are supported, got %s", ref)
+	}
+
+	definitions, _ := converter.root[path[0]].(map[string]interface{})
+	definition, ok := definitions[path[1]].(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("reference %s not found", ref)
+	}
+
+	name := common2.ToPascalCase(path[1])
+	if title, ok := definition["title"].(string); ok && title != "" {
+		name = common2.ToPascalCase(title)
+	}
+
+	// model is already generated (or is being generated in case of recursive types)
+	if converter.modelNames[name] {
+		return converter.getObjectMapping(name), name, nil
+	}
+
+	return converter.resolveType(definition, name)
+}
+
+func (converter *jsonSchemaConverter) addModel(schema map[string]interface{}, properties map[string]interface{}, name string) error {
+	if converter.modelNames[name] {
+		return nil
+	}
+	converter.modelNames[name] = true
+
+	required := make(map[string]bool)
+	if requiredList, ok := schema["required"].([]interface{}); ok {
+		for _, requiredName := range requiredList {
+			required[fmt.Sprint(requiredName)] = true
+		}
+	}
+
+	// map keys are not ordered, sort them to keep generation stable
+	jsonNames := make([]string, 0, len(properties))
+	for jsonName := range properties {
+		jsonNames = append(jsonNames, jsonName)
+	}
+	sort.Strings(jsonNames)
+
+	model := JsonModel{
+		Name:        name,
+		Description: getJsonSchemaString(schema, "description"),
+		Properties:  make([]JsonProperty, 0, len(properties)),
+	}
+
+	for _, jsonName := range jsonNames {
+		propertySchema, ok := properties[jsonName].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("property %s of %s is not valid schema", jsonName, name)
+		}
+
+		propertyName := common2.ToPascalCase(jsonName)
+		propertyMapping, _, err := converter.resolveType(propertySchema, name+propertyName)
+		if err != nil {
+			return fmt.Errorf("property %s of %s: %s", jsonName, name, err)
+		}
+
+		_, nullable := getJsonSchemaType(propertySchema)
+
+		model.Properties = append(model.Properties, JsonProperty{
+			JsonName:     jsonName,
+			PropertyName: propertyName,
+			PropertyType: propertyMapping.mappedType,
+			Description:  getJsonSchemaString(propertySchema, "description"),
+			Required:     required[jsonName],
+			Nullable:     nullable,
+		})
+	}
+
+	converter.models = append(converter.models, model)
+	return nil
+}
+
+func (converter *jsonSchemaConverter) getTypeMapping(jsonType string) (*mapping, error) {
+	typeMapping, err := getTypeMapping(jsonType, converter.globalMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fallbackType := range typeMapping.fallbackTypes {
+		if !slices.Contains(converter.fallbackTypes, fallbackType) {
+			converter.fallbackTypes = append(converter.fallbackTypes, fallbackType)
+		}
+	}
+
+	return typeMapping, nil
+}
+
+func (converter *jsonSchemaConverter) getObjectMapping(modelName string) *mapping {
+	objectMapping, exists := (*converter.globalMappings)[jsonObjectKey]
+	if !exists {
+		return &mapping{mappedType: modelName}
+	}
+
+	return applyTypePlaceholder(objectMapping, modelName)
+}
+
+// getJsonSchemaType returns type other than null and if null is allowed
+func getJsonSchemaType(schema map[string]interface{}) (string, bool) {
+	nullable, _ := schema["nullable"].(bool)
+
+	switch schemaType := schema["type"].(type) {
+	case string:
+		return schemaType, nullable || schemaType == "null"
+	case []interface{}:
+		resultType := ""
+		for _, item := range schemaType {
+			if item == "null" {
+				nullable = true
+			} else if resultType == "" {
+				resultType = fmt.Sprint(item)
+			}
+		}
+		return resultType, nullable
+	}
+
+	// objects are often defined only with properties
+	if _, hasProperties := schema["properties"]; hasProperties {
+		return "object", nullable
+	}
+
+	return "", nullable
+}
+
+func getJsonSchemaString(schema map[string]interface{}, key string) string {
+	value, _ := schema[key].(string)
+	return value
+}
+
+// loadJsonSchema accepts inline schema or path to schema file relative to configuration
+func loadJsonSchema(value interface{}, config *Config) (map[string]interface{}, error) {
+	switch schema := value.(type) {
+	case map[string]interface{}:
+		return schema, nil
+	case string:
+		schemaPath := joinIfRelative(config.PathBase, schema)
+		loaded := make(map[string]interface{})
+
+		err := common2.LoadFromJson(schemaPath, &loaded)
+		if err != nil {
+			return nil, fmt.Errorf("loading json schema %s: %s", schemaPath, err)
+		}
+
+		return loaded, nil
+	default:
+		return nil, fmt.Errorf("json schema has to be object or path to file")
+	}
+}
+
+// parseJsonSchemaDirective reads @schema directive from column comment, it can be followed by inline schema or file path
+func parseJsonSchemaDirective(comment string) (interface{}, error) {
+	index := strings.Index(comment, jsonSchemaDirective)
+	if index == -1 {
+		return nil, nil
+	}
+
+	rest := strings.TrimSpace(comment[index+len(jsonSchemaDirective):])
+	if strings.HasPrefix(rest, "{") {
+		schema := make(map[string]interface{})
+
+		err := json.NewDecoder(strings.NewReader(rest)).Decode(&schema)
+		if err != nil {
+			return nil, fmt.Errorf("parsing inline json schema in comment: %s", err)
+		}
+
+		return schema, nil
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s directive has to be followed by schema or file path", jsonSchemaDirective)
+	}
+
+	return filepath.FromSlash(fields[0]), nil
+}
+
+// restoreJsonSchemas replaces inline json schemas with raw values, because viper lowercases property names.
+// rawPath is path to the configuration object in raw configuration (empty for root configuration)
+func restoreJsonSchemas(config *Config, rawPath ...interface{}) {
+	for i, schemaConfig := range config.Generate {
+		for functionName, routineMapping := range schemaConfig.Functions {
+			for columnName, columnMapping := range routineMapping.Model {
+				if _, isInline := columnMapping.JsonSchema.(map[string]interface{}); !isInline {
+					continue
+				}
+
+				rawSchema, exists := getRawConfigValue(append(rawPath, "Generate", i, "Functions", functionName, "Model", columnName, "JsonSchema")...)
+				if exists {
+					columnMapping.JsonSchema = rawSchema
+					routineMapping.Model[columnName] = columnMapping
+				}
+			}
+
+			for paramName, paramMapping := range routineMapping.Parameters {
+				if _, isInline := paramMapping.JsonSchema.(map[string]interface{}); !isInline {
+					continue
+				}
+
+				rawSchema, exists := getRawConfigValue(append(rawPath, "Generate", i, "Functions", functionName, "Parameters", paramName, "JsonSchema")...)
+				if exists {
+					paramMapping.JsonSchema = rawSchema
+					routineMapping.Parameters[paramName] = paramMapping
+				}
+			}
+		}
+	}
+}
+
+// collectJsonModels returns distinct json models of all routines
+func collectJsonModels(routines []Routine) ([]JsonModel, error) {
+	models := make([]JsonModel, 0)
+	modelsByName := make(map[string]JsonModel)
+
+	for _, routine := range routines {
+		for _, model := range routine.JsonModels {
+			existing, exists := modelsByName[model.Name]
+			if !exists {
+				modelsByName[model.Name] = model
+				models = append(models, model)
+				continue
+			}
+
+			if common2.ToJson(existing) != common2.ToJson(model) {
+				return nil, fmt.Errorf("json model %s is generated from different schemas, set title to make names unique", model.Name)
+			}
+		}
+	}
+
+	return models, nil
+}
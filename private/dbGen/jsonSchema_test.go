@@ -0,0 +1,215 @@
+package dbGen
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func getJsonTestMappings() *map[string]mapping {
+	return &map[string]mapping{
+		"jsonb":                 {mappedType: "string", mappedFunction: "GetString"},
+		"json:string":           {mappedType: "string"},
+		"json:string:date-time": {mappedType: "DateTime"},
+		"json:integer":          {mappedType: "int"},
+		"json:boolean":          {mappedType: "bool"},
+		"json:array":            {mappedType: "List<{{.}}>"},
+		"json:object":           {mappedType: "{{.}}", mappedFunction: "GetFromJson<{{.}}>"},
+		"json:any":              {mappedType: "object"},
+	}
+}
+
+func parseTestSchema(t *testing.T, schema string) map[string]interface{} {
+	t.Helper()
+
+	parsed := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(schema), &parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed
+}
+
+func TestMapJsonSchema(t *testing.T) {
+	tests := []struct {
+		name         string
+		schema       string
+		wantType     string
+		wantFunction string
+		wantRoot     string
+		wantModels   []JsonModel
+	}{
+		{
+			name:         "nested objects",
+			schema:       `{"title": "user", "properties": {"address": {"properties": {"city": {"type": "string"}}}, "name": {"type": "string"}}}`,
+			wantType:     "User",
+			wantFunction: "GetFromJson<User>",
+			wantRoot:     "User",
+			wantModels: []JsonModel{
+				{Name: "UserAddress", Properties: []JsonProperty{{JsonName: "city", PropertyName: "City", PropertyType: "string"}}},
+				{Name: "User", Properties: []JsonProperty{
+					{JsonName: "address", PropertyName: "Address", PropertyType: "UserAddress"},
+					{JsonName: "name", PropertyName: "Name", PropertyType: "string"},
+				}},
+			},
+		},
+		{
+			name:         "array of objects",
+			schema:       `{"type": "array", "items": {"properties": {"id": {"type": "integer"}, "tags": {"type": "array", "items": {"type": "string"}}}}}`,
+			wantType:     "List<RowsItem>",
+			wantFunction: "",
+			wantRoot:     "RowsItem",
+			wantModels: []JsonModel{
+				{Name: "RowsItem", Properties: []JsonProperty{
+					{JsonName: "id", PropertyName: "Id", PropertyType: "int"},
+					{JsonName: "tags", PropertyName: "Tags", PropertyType: "List<string>"},
+				}},
+			},
+		},
+		{
+			name: "references to definitions",
+			schema: `{"title": "Document", "properties": {"owner": {"$ref": "#/definitions/person"}, "editor": {"$ref": "#/$defs/person"}},
+				"definitions": {"person": {"description": "Author", "properties": {"name": {"type": "string"}}}},
+				"$defs": {"person": {"title": "editor", "properties": {"created": {"type": "string", "format": "date-time"}}}}}`,
+			wantType:     "Document",
+			wantFunction: "GetFromJson<Document>",
+			wantRoot:     "Document",
+			wantModels: []JsonModel{
+				{Name: "Editor", Properties: []JsonProperty{{JsonName: "created", PropertyName: "Created", PropertyType: "DateTime"}}},
+				{Name: "Person", Description: "Author", Properties: []JsonProperty{{JsonName: "name", PropertyName: "Name", PropertyType: "string"}}},
+				{Name: "Document", Properties: []JsonProperty{
+					{JsonName: "editor", PropertyName: "Editor", PropertyType: "Editor"},
+					{JsonName: "owner", PropertyName: "Owner", PropertyType: "Person"},
+				}},
+			},
+		},
+		{
+			name:         "recursive reference",
+			schema:       `{"$ref": "#/definitions/node", "definitions": {"node": {"properties": {"children": {"type": "array", "items": {"$ref": "#/definitions/node"}}}}}}`,
+			wantType:     "Node",
+			wantFunction: "GetFromJson<Node>",
+			wantRoot:     "Node",
+			wantModels: []JsonModel{
+				{Name: "Node", Properties: []JsonProperty{{JsonName: "children", PropertyName: "Children", PropertyType: "List<Node>"}}},
+			},
+		},
+		{
+			name: "nullable and required",
+			schema: `{"title": "Flags", "required": ["required", "requiredNullable"], "properties": {
+				"required": {"type": "boolean"},
+				"requiredNullable": {"type": ["boolean", "null"]},
+				"nullable": {"type": "string", "nullable": true},
+				"optional": {"description": "Not required", "type": "integer"}}}`,
+			wantType:     "Flags",
+			wantFunction: "GetFromJson<Flags>",
+			wantRoot:     "Flags",
+			wantModels: []JsonModel{
+				{Name: "Flags", Properties: []JsonProperty{
+					{JsonName: "nullable", PropertyName: "Nullable", PropertyType: "string", Nullable: true},
+					{JsonName: "optional", PropertyName: "Optional", PropertyType: "int", Description: "Not required"},
+					{JsonName: "required", PropertyName: "Required", PropertyType: "bool", Required: true},
+					{JsonName: "requiredNullable", PropertyName: "RequiredNullable", PropertyType: "bool", Required: true, Nullable: true},
+				}},
+			},
+		},
+		{
+			name:         "object without properties",
+			schema:       `{"type": "object"}`,
+			wantType:     "object",
+			wantFunction: "",
+			wantRoot:     "",
+			wantModels:   []JsonModel{},
+		},
+	}
+
+	for _, test := range tests {
+		typeMapping, rootModel, models, err := mapJsonSchema(parseTestSchema(t, test.schema), "Rows", getJsonTestMappings())
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if typeMapping.mappedType != test.wantType || typeMapping.mappedFunction != test.wantFunction || rootModel != test.wantRoot {
+			t.Errorf("%s: got type %s, function %s, root model %s, want %s, %s, %s",
+				test.name, typeMapping.mappedType, typeMapping.mappedFunction, rootModel, test.wantType, test.wantFunction, test.wantRoot)
+		}
+
+		if !reflect.DeepEqual(models, test.wantModels) {
+			t.Errorf("%s: got models\n%+v\nwant\n%+v", test.name, models, test.wantModels)
+		}
+	}
+}
+
+func TestMapJsonSchemaInvalidReference(t *testing.T) {
+	for _, ref := range []string{"other.json#/definitions/person", "#/definitions/missing", "#/definitions/person/properties/name"} {
+		schema := map[string]interface{}{"$ref": ref}
+
+		_, _, _, err := mapJsonSchema(schema, "Rows", getJsonTestMappings())
+		if err == nil {
+			t.Errorf("reference %s didn't fail", ref)
+		}
+	}
+}
+
+func TestJsonSchemaPrecedence(t *testing.T) {
+	configFolder := t.TempDir()
+	writeFolder(t, configFolder, map[string]string{
+		"schemas/file.json":    `{"title": "FromFile", "properties": {"a": {"type": "string"}}}`,
+		"schemas/comment.json": `{"title": "FromCommentFile", "properties": {"a": {"type": "string"}}}`,
+	})
+
+	// @schema file path is relative to configuration, not to working directory
+	workingFolder, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(workingFolder)
+
+	inline := map[string]interface{}{"title": "Inline", "properties": map[string]interface{}{"a": map[string]interface{}{"type": "string"}}}
+
+	tests := []struct {
+		name     string
+		comment  string
+		mapping  *ColumnMapping
+		wantType string
+	}{
+		{"no schema", "", nil, "string"},
+		{"inline schema in comment", `@schema {"title": "FromComment", "properties": {"a": {"type": "string"}}}`, nil, "FromComment"},
+		{"file in comment", "Data of user @schema ./schemas/comment.json", nil, "FromCommentFile"},
+		{"inline schema in configuration", "", &ColumnMapping{SelectColumn: true, JsonSchema: inline}, "Inline"},
+		{"file in configuration", "", &ColumnMapping{SelectColumn: true, JsonSchema: "./schemas/file.json"}, "FromFile"},
+		{"configuration schema wins over comment", "@schema ./schemas/comment.json", &ColumnMapping{SelectColumn: true, JsonSchema: "./schemas/file.json"}, "FromFile"},
+		{"configuration type wins over comment", "@schema ./schemas/comment.json", &ColumnMapping{SelectColumn: true, MappedType: "string", MappingFunction: "GetRaw"}, "string"},
+		{"other configuration keeps comment", "@schema ./schemas/comment.json", &ColumnMapping{SelectColumn: true, MappedName: "Data"}, "FromCommentFile"},
+	}
+
+	for _, test := range tests {
+		config := &Config{PathBase: configFolder}
+		routineMapping := &RoutineMapping{Model: map[string]ColumnMapping{}}
+		if test.mapping != nil {
+			routineMapping.Model["data"] = *test.mapping
+		}
+
+		param := DbParameter{Name: "data", UDTName: "jsonb", Comment: test.comment}
+
+		_, effectiveMapping, err := getColumnMapping(param, routineMapping, getJsonTestMappings(), config)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		_, err = applyJsonSchema(effectiveMapping, "GetUsers", getJsonTestMappings(), config)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if effectiveMapping.typeMapping.mappedType != test.wantType {
+			t.Errorf("%s: got type %s, want %s", test.name, effectiveMapping.typeMapping.mappedType, test.wantType)
+		}
+	}
+}
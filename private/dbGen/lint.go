@@ -0,0 +1,529 @@
+package dbGen
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Templates are linted without database, field chains are type checked against template data of output scope.
+// Values of unknown type (interface{} like .Vars or results of some functions) are not checked
+
+type LintError struct {
+	Location string // file:line:column
+	Message  string
+}
+
+func (e LintError) String() string {
+	return e.Location + ": " + e.Message
+}
+
+// result types of built-in text/template functions, nil if it depends on arguments (see getBuiltinResultType)
+var builtinResultTypes = map[string]reflect.Type{
+	"not":      reflect.TypeOf(false),
+	"eq":       reflect.TypeOf(false),
+	"ne":       reflect.TypeOf(false),
+	"lt":       reflect.TypeOf(false),
+	"le":       reflect.TypeOf(false),
+	"gt":       reflect.TypeOf(false),
+	"ge":       reflect.TypeOf(false),
+	"len":      reflect.TypeOf(0),
+	"print":    reflect.TypeOf(""),
+	"printf":   reflect.TypeOf(""),
+	"println":  reflect.TypeOf(""),
+	"html":     reflect.TypeOf(""),
+	"js":       reflect.TypeOf(""),
+	"urlquery": reflect.TypeOf(""),
+	"and":      nil,
+	"or":       nil,
+	"call":     nil,
+	"index":    nil,
+	"slice":    nil,
+}
+
+// functions returning items of their last argument, so the element type is kept: {{range sortBy "Name" .Functions}}
+var listFunctions = []string{"sortBy", "filter", "uniq", "withTag"}
+var itemFunctions = []string{"first", "last"}
+
+// getScopeDataTypes returns types of template data and of Filter and Path data
+func getScopeDataTypes(scope string) (reflect.Type, reflect.Type) {
+	switch scope {
+	case ScopeGlobal:
+		return reflect.TypeOf(&DbContextData{}), reflect.TypeOf(&DbContextData{})
+	case ScopeSchema:
+		return reflect.TypeOf(&SchemaTemplateData{}), reflect.TypeOf(&SchemaTemplateData{})
+	case ScopeRoutine:
+		return reflect.TypeOf(&ProcessorTemplateData{}), reflect.TypeOf(Routine{})
+	case ScopeModel:
+		return reflect.TypeOf(&ModelTemplateData{}), reflect.TypeOf(Routine{})
+	case ScopeEnum:
+		return reflect.TypeOf(&EnumTemplateData{}), reflect.TypeOf(Enum{})
+	case ScopeCustomType:
+		return reflect.TypeOf(&CustomTypeTemplateData{}), reflect.TypeOf(CustomType{})
+	case ScopeJsonModel:
+		return reflect.TypeOf(&JsonModelTemplateData{}), reflect.TypeOf(JsonModel{})
+	default:
+		return nil, nil
+	}
+}
+
+// LintTemplates checks templates, filters and paths of all outputs
+func LintTemplates(config *Config) ([]LintError, error) {
+	partials, err := loadTemplatePartials(config)
+	if err != nil {
+		return nil, fmt.Errorf("loading template partials: %s", err)
+	}
+
+	// partial files, parse name of partial is its name
+	files := make(map[string]string)
+	for _, partial := range partials {
+		files[partial.name] = partial.path
+	}
+
+	lintErrors := make([]LintError, 0)
+	for _, output := range config.Outputs {
+		templateType, pathType := getScopeDataTypes(output.Scope)
+
+		override, err := loadTemplateOverride(output)
+		if err != nil {
+			return nil, err
+		}
+
+		outputTemplate, err := parseTemplate(output.Template, override, partials)
+		if err != nil {
+			lintErrors = append(lintErrors, LintError{Location: output.Template, Message: err.Error()})
+		} else {
+			files[path.Base(filepath.ToSlash(output.Template))] = output.Template
+			if override != nil {
+				files[override.name] = override.path
+			}
+			lintErrors = append(lintErrors, lintTemplate(outputTemplate, templateType, files)...)
+		}
+
+		for _, inline := range [][2]string{{"filter", output.Filter}, {"path", output.Path}} {
+			name, text := inline[0], inline[1]
+			location := fmt.Sprintf("output %s %s", output.Name, name)
+
+			inlineTemplate, err := parseInlineTemplate(name, text)
+			if err != nil {
+				lintErrors = append(lintErrors, LintError{Location: location, Message: err.Error()})
+				continue
+			}
+
+			lintErrors = append(lintErrors, lintTemplate(inlineTemplate, pathType, map[string]string{name: location})...)
+		}
+	}
+
+	return uniqueLintErrors(lintErrors), nil
+}
+
+// uniqueLintErrors removes duplicates, partials used by multiple outputs would be reported multiple times
+func uniqueLintErrors(lintErrors []LintError) []LintError {
+	unique := make([]LintError, 0, len(lintErrors))
+	for _, lintError := range lintErrors {
+		if !slices.Contains(unique, lintError) {
+			unique = append(unique, lintError)
+		}
+	}
+
+	return unique
+}
+
+type templateLinter struct {
+	tmpl      *template.Template
+	files     map[string]string // parse name -> file
+	functions map[string]reflect.Type
+	checked   map[string]bool // template name and data type, so every template is checked once for each type
+	errors    []LintError
+}
+
+// lintScope holds type of dot and declared variables, nil type means that type is not known
+type lintScope struct {
+	dot  reflect.Type
+	vars map[string]reflect.Type
+}
+
+func (s *lintScope) child(dot reflect.Type) *lintScope {
+	vars := make(map[string]reflect.Type, len(s.vars))
+	for name, varType := range s.vars {
+		vars[name] = varType
+	}
+
+	return &lintScope{dot: dot, vars: vars}
+}
+
+func lintTemplate(tmpl *template.Template, dataType reflect.Type, files map[string]string) []LintError {
+	functions := make(map[string]reflect.Type)
+	for name, function := range getTemplateFunctions() {
+		functions[name] = reflect.TypeOf(function)
+	}
+	functions["include"] = reflect.TypeOf(getIncludeFunction(tmpl))
+
+	linter := &templateLinter{
+		tmpl:      tmpl,
+		files:     files,
+		functions: functions,
+		checked:   make(map[string]bool),
+		errors:    make([]LintError, 0),
+	}
+
+	linter.checkTemplate(tmpl.Name(), dataType)
+
+	return linter.errors
+}
+
+func (l *templateLinter) checkTemplate(name string, dataType reflect.Type) {
+	key := fmt.Sprintf("%s|%v", name, dataType)
+	if l.checked[key] {
+		return
+	}
+	l.checked[key] = true
+
+	tmpl := l.tmpl.Lookup(name)
+	if tmpl == nil || tmpl.Tree == nil || tmpl.Tree.Root == nil {
+		return
+	}
+
+	// $ is data of executed template
+	scope := &lintScope{dot: dataType, vars: map[string]reflect.Type{"$": dataType}}
+	l.walk(tmpl.Tree, tmpl.Tree.Root, scope)
+}
+
+func (l *templateLinter) addError(tree *parse.Tree, node parse.Node, format string, args ...interface{}) {
+	location, _ := tree.ErrorContext(node)
+	if file, exists := l.files[tree.ParseName]; exists {
+		location = file + strings.TrimPrefix(location, tree.ParseName)
+	}
+
+	l.errors = append(l.errors, LintError{Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *templateLinter) walk(tree *parse.Tree, node parse.Node, scope *lintScope) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+
+		for _, child := range node.Nodes {
+			l.walk(tree, child, scope)
+		}
+	case *parse.ActionNode:
+		l.pipeType(tree, node.Pipe, scope)
+	case *parse.IfNode:
+		branchScope := scope.child(scope.dot)
+		l.pipeType(tree, node.Pipe, branchScope)
+		l.walk(tree, node.List, branchScope.child(scope.dot))
+		l.walk(tree, node.ElseList, branchScope.child(scope.dot))
+	case *parse.WithNode:
+		branchScope := scope.child(scope.dot)
+		withType := l.pipeType(tree, node.Pipe, branchScope)
+		l.walk(tree, node.List, branchScope.child(withType))
+		l.walk(tree, node.ElseList, branchScope.child(scope.dot))
+	case *parse.RangeNode:
+		l.walkRange(tree, node, scope)
+	case *parse.TemplateNode:
+		var argumentType reflect.Type
+		if node.Pipe != nil {
+			argumentType = l.pipeType(tree, node.Pipe, scope)
+		}
+
+		l.checkTemplateReference(tree, node, node.Name, argumentType)
+	}
+}
+
+func (l *templateLinter) walkRange(tree *parse.Tree, node *parse.RangeNode, scope *lintScope) {
+	rangeScope := scope.child(scope.dot)
+
+	// variables are declared by range, not by pipeline
+	rangeType := l.pipelineType(tree, node.Pipe, rangeScope)
+	declarations := node.Pipe.Decl
+
+	var keyType, itemType reflect.Type
+	if rangeType != nil {
+		rangeType = indirectType(rangeType)
+
+		switch rangeType.Kind() {
+		case reflect.Slice, reflect.Array:
+			keyType, itemType = reflect.TypeOf(0), rangeType.Elem()
+		case reflect.Map:
+			keyType, itemType = rangeType.Key(), rangeType.Elem()
+		case reflect.Interface:
+		default:
+			l.addError(tree, node, "range can't iterate over %s", rangeType)
+		}
+	}
+
+	itemType = knownType(itemType)
+
+	switch len(declarations) {
+	case 1:
+		rangeScope.vars[declarations[0].Ident[0]] = itemType
+	case 2:
+		rangeScope.vars[declarations[0].Ident[0]] = keyType
+		rangeScope.vars[declarations[1].Ident[0]] = itemType
+	}
+
+	l.walk(tree, node.List, rangeScope.child(itemType))
+	l.walk(tree, node.ElseList, scope.child(scope.dot))
+}
+
+func (l *templateLinter) checkTemplateReference(tree *parse.Tree, node parse.Node, name string, argumentType reflect.Type) {
+	if l.tmpl.Lookup(name) == nil {
+		l.addError(tree, node, "template %q is not defined", name)
+		return
+	}
+
+	l.checkTemplate(name, argumentType)
+}
+
+// pipeType returns type of pipeline result and declares its variables
+func (l *templateLinter) pipeType(tree *parse.Tree, pipe *parse.PipeNode, scope *lintScope) reflect.Type {
+	if pipe == nil {
+		return nil
+	}
+
+	result := l.pipelineType(tree, pipe, scope)
+	if !pipe.IsAssign {
+		for _, variable := range pipe.Decl {
+			scope.vars[variable.Ident[0]] = result
+		}
+	}
+
+	return result
+}
+
+func (l *templateLinter) pipelineType(tree *parse.Tree, pipe *parse.PipeNode, scope *lintScope) reflect.Type {
+	var result reflect.Type
+	for i, command := range pipe.Cmds {
+		// result of previous command is passed as last argument
+		var piped []reflect.Type
+		if i > 0 {
+			piped = []reflect.Type{result}
+		}
+
+		result = l.commandType(tree, command, scope, piped)
+	}
+
+	return result
+}
+
+func (l *templateLinter) commandType(tree *parse.Tree, command *parse.CommandNode, scope *lintScope, piped []reflect.Type) reflect.Type {
+	if identifier, isFunction := command.Args[0].(*parse.IdentifierNode); isFunction {
+		return l.functionType(tree, command, identifier.Ident, command.Args[1:], scope, piped)
+	}
+
+	// method arguments are checked, but methods are not called with them
+	for _, argument := range command.Args[1:] {
+		l.nodeType(tree, argument, scope)
+	}
+
+	return l.nodeType(tree, command.Args[0], scope)
+}
+
+func (l *templateLinter) nodeType(tree *parse.Tree, node parse.Node, scope *lintScope) reflect.Type {
+	switch node := node.(type) {
+	case *parse.DotNode:
+		return scope.dot
+	case *parse.FieldNode:
+		return l.fieldType(tree, node, scope.dot, node.Ident)
+	case *parse.VariableNode:
+		return l.fieldType(tree, node, scope.vars[node.Ident[0]], node.Ident[1:])
+	case *parse.ChainNode:
+		return l.fieldType(tree, node, l.nodeType(tree, node.Node, scope), node.Field)
+	case *parse.PipeNode:
+		return l.pipeType(tree, node, scope.child(scope.dot))
+	case *parse.IdentifierNode:
+		return l.functionType(tree, node, node.Ident, nil, scope, nil)
+	case *parse.StringNode:
+		return reflect.TypeOf("")
+	case *parse.BoolNode:
+		return reflect.TypeOf(false)
+	default:
+		// numbers are converted to type of argument, nil can be anything
+		return nil
+	}
+}
+
+// fieldType resolves chain of fields (or map keys and methods) the same way as text/template
+func (l *templateLinter) fieldType(tree *parse.Tree, node parse.Node, base reflect.Type, fields []string) reflect.Type {
+	current := base
+
+	for _, field := range fields {
+		if current == nil {
+			return nil
+		}
+
+		if method, exists := reflect.PointerTo(indirectType(current)).MethodByName(field); exists {
+			if method.Type.NumOut() == 0 {
+				return nil
+			}
+
+			current = knownType(method.Type.Out(0))
+			continue
+		}
+
+		current = indirectType(current)
+
+		switch current.Kind() {
+		case reflect.Struct:
+			structField, exists := current.FieldByName(field)
+			if !exists || !structField.IsExported() {
+				l.addError(tree, node, "%s has no field %s", current, field)
+				return nil
+			}
+
+			current = knownType(structField.Type)
+		case reflect.Map:
+			if current.Key().Kind() != reflect.String {
+				l.addError(tree, node, "can't get key %s of %s", field, current)
+				return nil
+			}
+
+			current = knownType(current.Elem())
+		case reflect.Interface:
+			return nil
+		default:
+			l.addError(tree, node, "can't get field %s of %s", field, current)
+			return nil
+		}
+	}
+
+	return current
+}
+
+// functionType checks arguments of function call and returns its result type, piped value is the last argument
+func (l *templateLinter) functionType(tree *parse.Tree, node parse.Node, name string, arguments []parse.Node, scope *lintScope, piped []reflect.Type) reflect.Type {
+	argumentTypes := make([]reflect.Type, 0, len(arguments)+len(piped))
+	for _, argument := range arguments {
+		argumentTypes = append(argumentTypes, l.nodeType(tree, argument, scope))
+	}
+	argumentTypes = append(argumentTypes, piped...)
+
+	if name == "include" && len(arguments) > 0 && len(argumentTypes) == 2 {
+		if templateName, isString := arguments[0].(*parse.StringNode); isString {
+			l.checkTemplateReference(tree, node, templateName.Text, argumentTypes[1])
+		}
+	}
+
+	if resultType, isBuiltin := builtinResultTypes[name]; isBuiltin {
+		return getBuiltinResultType(name, resultType, argumentTypes)
+	}
+
+	functionType, exists := l.functions[name]
+	if !exists {
+		// parser checks that functions exist
+		return nil
+	}
+
+	argumentCount := len(argumentTypes)
+	parameterCount := functionType.NumIn()
+	if functionType.IsVariadic() && argumentCount < parameterCount-1 {
+		l.addError(tree, node, "%s expects at least %d arguments, got %d", name, parameterCount-1, argumentCount)
+		return nil
+	}
+	if !functionType.IsVariadic() && argumentCount != parameterCount {
+		l.addError(tree, node, "%s expects %d arguments, got %d", name, parameterCount, argumentCount)
+		return nil
+	}
+
+	for i, argumentType := range argumentTypes {
+		parameterType := getParameterType(functionType, i)
+		if !isAssignableArgument(argumentType, parameterType) {
+			l.addError(tree, node, "argument %d of %s has to be %s, got %s", i+1, name, parameterType, argumentType)
+		}
+	}
+
+	lastArgumentType := reflect.Type(nil)
+	if argumentCount > 0 && argumentTypes[argumentCount-1] != nil {
+		lastArgumentType = indirectType(argumentTypes[argumentCount-1])
+	}
+
+	switch {
+	case slices.Contains(listFunctions, name) && isListType(lastArgumentType):
+		return reflect.SliceOf(lastArgumentType.Elem())
+	case slices.Contains(itemFunctions, name) && isListType(lastArgumentType):
+		return knownType(lastArgumentType.Elem())
+	case name == "default":
+		return lastArgumentType
+	}
+
+	if functionType.NumOut() == 0 {
+		return nil
+	}
+
+	return knownType(functionType.Out(0))
+}
+
+func getBuiltinResultType(name string, resultType reflect.Type, argumentTypes []reflect.Type) reflect.Type {
+	if len(argumentTypes) == 0 || argumentTypes[0] == nil {
+		return resultType
+	}
+
+	switch name {
+	case "slice":
+		return argumentTypes[0]
+	case "index":
+		current := argumentTypes[0]
+		for range argumentTypes[1:] {
+			current = indirectType(current)
+			if !isListType(current) && current.Kind() != reflect.Map {
+				return nil
+			}
+
+			current = knownType(current.Elem())
+			if current == nil {
+				return nil
+			}
+		}
+
+		return current
+	default:
+		return resultType
+	}
+}
+
+func getParameterType(functionType reflect.Type, index int) reflect.Type {
+	if functionType.IsVariadic() && index >= functionType.NumIn()-1 {
+		return functionType.In(functionType.NumIn() - 1).Elem()
+	}
+
+	return functionType.In(index)
+}
+
+// isAssignableArgument reports incompatible argument only when both types are known, pointers are dereferenced by text/template
+func isAssignableArgument(argumentType reflect.Type, parameterType reflect.Type) bool {
+	if argumentType == nil || parameterType.Kind() == reflect.Interface {
+		return true
+	}
+
+	return argumentType.AssignableTo(parameterType) ||
+		indirectType(argumentType).AssignableTo(parameterType) ||
+		reflect.PointerTo(argumentType).AssignableTo(parameterType)
+}
+
+func isListType(t reflect.Type) bool {
+	return t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// knownType returns nil for interfaces, their values can be anything
+func knownType(t reflect.Type) reflect.Type {
+	if t == nil || t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	return t
+}
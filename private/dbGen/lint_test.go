@@ -0,0 +1,182 @@
+package dbGen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lintOutput lints template with content in routine scope, partials are written to folder of TemplatePartials
+func lintOutput(t *testing.T, content string, partials map[string]string) (string, []LintError) {
+	t.Helper()
+
+	folder := t.TempDir()
+	templatePath := filepath.Join(folder, "processor.gotmpl")
+	if err := os.WriteFile(templatePath, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{
+		Outputs: []OutputConfig{{
+			Name:     "processors",
+			Template: templatePath,
+			Scope:    ScopeRoutine,
+			Path:     "{{.FunctionName}}.cs",
+		}},
+	}
+
+	if len(partials) > 0 {
+		config.TemplatePartials = filepath.Join(folder, "partials")
+		writeFolder(t, config.TemplatePartials, partials)
+	}
+
+	lintErrors, err := LintTemplates(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return templatePath, lintErrors
+}
+
+func TestLintTemplatesReportsFieldLocation(t *testing.T) {
+	templatePath, lintErrors := lintOutput(t, "class {{.Routine.FunctionName}}\n{\n    {{.Routine.ModleName}} Model;\n}\n", nil)
+
+	want := LintError{Location: templatePath + ":3:14", Message: "dbGen.Routine has no field ModleName"}
+	if len(lintErrors) != 1 || lintErrors[0] != want {
+		t.Errorf("got %v, want %v", lintErrors, []LintError{want})
+	}
+}
+
+func TestLintTemplatesReportsUnknownFunction(t *testing.T) {
+	templatePath, lintErrors := lintOutput(t, "{{.Routine.FunctionName | camelcase}}\n", nil)
+
+	if len(lintErrors) != 1 || lintErrors[0].Location != templatePath || !strings.Contains(lintErrors[0].Message, `function "camelcase" not defined`) {
+		t.Errorf("got %v, want error about undefined function camelcase", lintErrors)
+	}
+}
+
+func TestLintTemplatesReportsMissingPartial(t *testing.T) {
+	partials := map[string]string{"header.gotmpl": "{{define \"header\"}}// {{.Routine.FunctionName}}{{end}}\n"}
+	content := "{{template \"header\" .}}\n{{template \"footer\" .}}\n{{include \"signature\" .}}\n"
+
+	templatePath, lintErrors := lintOutput(t, content, partials)
+
+	want := []LintError{
+		{Location: templatePath + ":2:11", Message: `template "footer" is not defined`},
+		{Location: templatePath + ":3:2", Message: `template "signature" is not defined`},
+	}
+	if len(lintErrors) != len(want) || lintErrors[0] != want[0] || lintErrors[1] != want[1] {
+		t.Errorf("got %v, want %v", lintErrors, want)
+	}
+}
+
+func TestLintTemplatesChecksPartialWithArgumentType(t *testing.T) {
+	partials := map[string]string{"property.gotmpl": "{{define \"property\"}}\n{{.DbColumnName}} {{.ColumnName}}\n{{end}}\n"}
+	content := "{{range .Routine.ReturnProperties}}{{template \"property\" .}}{{end}}\n"
+
+	templatePath, lintErrors := lintOutput(t, content, partials)
+
+	// error is reported in partial file, checked with type of template argument
+	want := LintError{
+		Location: filepath.Join(filepath.Dir(templatePath), "partials", "property.gotmpl") + ":2:20",
+		Message:  "dbGen.Property has no field ColumnName",
+	}
+	if len(lintErrors) != 1 || lintErrors[0] != want {
+		t.Errorf("got %v, want %v", lintErrors, []LintError{want})
+	}
+}
+
+func TestLintTemplatesRebindsDot(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "range over properties",
+			content: "{{range .Routine.ReturnProperties}}{{.DbColumnName}}{{.FunctionName}}{{end}}",
+			want:    []string{"dbGen.Property has no field FunctionName"},
+		},
+		{
+			name:    "range with variables",
+			content: "{{range $i, $property := .Routine.ReturnProperties}}{{$property.DbColumnName}}{{$i.Name}}{{end}}",
+			want:    []string{"can't get field Name of int"},
+		},
+		{
+			name:    "root data in range",
+			content: "{{range .Routine.ReturnProperties}}{{$.Routine.FunctionName}}{{$.Routine.DbColumnName}}{{end}}",
+			want:    []string{"dbGen.Routine has no field DbColumnName"},
+		},
+		{
+			name:    "else of range keeps dot",
+			content: "{{range .Routine.ReturnProperties}}{{.DbColumnName}}{{else}}{{.Routine.FunctionName}}{{end}}",
+			want:    nil,
+		},
+		{
+			name:    "with",
+			content: "{{with .Routine.Sql}}{{.Positional}}{{.FunctionName}}{{end}}",
+			want:    []string{"dbGen.RoutineSql has no field FunctionName"},
+		},
+		{
+			name:    "else of with keeps dot",
+			content: "{{with .Routine.Sql}}{{.Positional}}{{else}}{{.Routine.FunctionName}}{{end}}",
+			want:    nil,
+		},
+		{
+			name:    "list function keeps element type",
+			content: "{{range sortBy \"DbColumnName\" .Routine.ReturnProperties}}{{.FunctionName}}{{end}}",
+			want:    []string{"dbGen.Property has no field FunctionName"},
+		},
+		{
+			name:    "unknown type is not checked",
+			content: "{{range .Vars}}{{.Anything}}{{end}}{{.Vars.Namespace.Anything}}",
+			want:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		_, lintErrors := lintOutput(t, test.content, nil)
+
+		messages := make([]string, 0)
+		for _, lintError := range lintErrors {
+			messages = append(messages, lintError.Message)
+		}
+
+		if strings.Join(messages, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s: got %v, want %v", test.name, messages, test.want)
+		}
+	}
+}
+
+func TestLintTemplatesBuiltinPacks(t *testing.T) {
+	names, err := GetTemplatePackNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		folder := t.TempDir()
+		config := &Config{PathBase: folder, OutputFolder: "output", TemplatePack: builtinPrefix + name}
+
+		err := resolveConfig(config)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+
+		if len(config.Outputs) == 0 {
+			t.Errorf("%s: pack has no outputs", name)
+		}
+
+		lintErrors, err := LintTemplates(config)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+
+		for _, lintError := range lintErrors {
+			t.Errorf("%s: %s", name, lintError)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package dbGen
+
+import (
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"io/fs"
+	"path/filepath"
+)
+
+// Generation information contains manifest of generated files, so files that are not generated anymore
+// (e.g. processor of deleted routine) can be deleted without touching other files in output folder
+
+// getOrphanedFiles returns files generated last time that are not generated anymore
+func getOrphanedFiles(previousInfo *GenerationInformation, generatedPaths []string) []string {
+	orphaned := make([]string, 0)
+	if previousInfo == nil {
+		return orphaned
+	}
+
+	generated := getPathSet(generatedPaths)
+
+	for _, file := range previousInfo.Files {
+		// manifest is only read, paths outside of output folder are never deleted
+		if generated[file] || !filepath.IsLocal(filepath.FromSlash(file)) {
+			continue
+		}
+
+		orphaned = append(orphaned, file)
+	}
+
+	return orphaned
+}
+
+// getDeletedFiles returns files that generate deletes, orphaned files or all files that are not generated
+// when ClearOutputFolder is set
+func getDeletedFiles(config *Config, previousInfo *GenerationInformation, generatedPaths []string) ([]string, error) {
+	if config.ClearOutputFolder {
+		return getClearedFiles(config, generatedPaths)
+	}
+
+	return getOrphanedFiles(previousInfo, generatedPaths), nil
+}
+
+// getClearedFiles returns files of output folder that are not generated (sorted by walk), generation information is kept
+func getClearedFiles(config *Config, generatedPaths []string) ([]string, error) {
+	cleared := make([]string, 0)
+	if !common2.PathExists(config.OutputFolder) {
+		return cleared, nil
+	}
+
+	generated := getPathSet(generatedPaths)
+
+	err := filepath.WalkDir(config.OutputFolder, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// folders of running or interrupted generation
+		if entry.IsDir() && isTransactionFolder(entry.Name()) {
+			return filepath.SkipDir
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(config.OutputFolder, filePath)
+		if err != nil {
+			return err
+		}
+
+		relPath = filepath.ToSlash(relPath)
+		if !generated[relPath] && relPath != generationInfoFileName {
+			cleared = append(cleared, relPath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing output folder: %s", err)
+	}
+
+	return cleared, nil
+}
+
+func getPathSet(paths []string) map[string]bool {
+	set := make(map[string]bool, len(paths))
+	for _, filePath := range paths {
+		set[filePath] = true
+	}
+
+	return set
+}
@@ -5,18 +5,25 @@ import (
 	common2 "github.com/keenmate/db-gen/private/helpers"
 	"slices"
 	"sort"
+	"strings"
 )
 
 type mapping struct {
 	mappedFunction string
 	mappedType     string
+	fallbackTypes  []string // database types that had to use fallback mapping
 }
 
 type effectiveParamMapping struct {
-	name        string
-	typeMapping mapping
-	isNullable  bool
-	isOptional  bool
+	name          string
+	typeMapping   mapping
+	typeParameter string
+	jsonSchema    interface{}
+	jsonModel     string
+	isNullable    bool
+	isOptional    bool
+	tags          []string
+	extra         map[string]interface{}
 }
 
 // TODO make configurable
@@ -24,8 +31,10 @@ const hiddenSchema = "public"
 
 const fallbackMappingKey = "*"
 
+const userDefinedType = "USER-DEFINED"
+
 // this data types represent structured data
-var structuredTypes = []string{"record", "USER-DEFINED"}
+var structuredTypes = []string{"record", userDefinedType}
 
 // data types that represents no return types
 var voidTypes = []string{"void"}
@@ -47,12 +56,7 @@ func mapRoutines(routines *[]DbRoutine, globalTypeMappings *map[string]mapping,
 		common2.LogDebug("Mapping %s", routine.RoutineName)
 		routineMapping := getRoutineMapping(routine, schemaConfig)
 
-		modelProperties, err := mapModel(routine, globalTypeMappings, &routineMapping, config)
-		if err != nil {
-			return nil, fmt.Errorf("processing function %s: %s", routine.RoutineName, err)
-		}
-
-		parameters, err := mapParameters(routine.InParameters, globalTypeMappings, &routineMapping, config)
+		err := validatePinnedTypeParameters(&routineMapping)
 		if err != nil {
 			return nil, fmt.Errorf("processing function %s: %s", routine.RoutineName, err)
 		}
@@ -62,6 +66,24 @@ func mapRoutines(routines *[]DbRoutine, globalTypeMappings *map[string]mapping,
 		modelName := getModelName(functionName)
 		processorName := getProcessorName(functionName)
 
+		modelProperties, modelJsonModels, err := mapModel(routine, functionName, globalTypeMappings, &routineMapping, config)
+		if err != nil {
+			return nil, fmt.Errorf("processing function %s: %s", routine.RoutineName, err)
+		}
+
+		parameters, parameterJsonModels, err := mapParameters(routine.InParameters, functionName, globalTypeMappings, &routineMapping, config)
+		if err != nil {
+			return nil, fmt.Errorf("processing function %s: %s", routine.RoutineName, err)
+		}
+
+		customType, customTypeJsonModels, err := getRoutineCustomType(routine, globalTypeMappings, config)
+		if err != nil {
+			return nil, fmt.Errorf("processing function %s: %s", routine.RoutineName, err)
+		}
+
+		jsonModels := append(parameterJsonModels, modelJsonModels...)
+		jsonModels = append(jsonModels, customTypeJsonModels...)
+
 		mappedRoutine := Routine{
 			FunctionName:       functionName,
 			DbFullFunctionName: routine.RoutineSchema + "." + routine.RoutineName,
@@ -69,10 +91,17 @@ func mapRoutines(routines *[]DbRoutine, globalTypeMappings *map[string]mapping,
 			Parameters:         parameters,
 			ReturnProperties:   modelProperties,
 			ProcessorName:      processorName,
+			TypeParameters:     getRoutineTypeParameters(parameters, modelProperties),
 			HasReturn:          len(modelProperties) > 0,
 			IsProcedure:        routine.FuncType == Procedure,
 			Schema:             routine.RoutineSchema,
 			DbFunctionName:     routine.RoutineName,
+			JsonModels:         jsonModels,
+			Enums:              getRoutineEnums(routine),
+			CustomType:         customType,
+			Sql:                getRoutineSql(routine, routine.InParameters, parameters, modelProperties),
+			Tags:               routineMapping.Tags,
+			Extra:              routineMapping.Extra,
 		}
 
 		mappedFunctions[i] = mappedRoutine
@@ -81,27 +110,38 @@ func mapRoutines(routines *[]DbRoutine, globalTypeMappings *map[string]mapping,
 	return mappedFunctions, nil
 }
 
-func mapModel(routine DbRoutine, globalTypeMappings *map[string]mapping, routineMapping *RoutineMapping, config *Config) ([]Property, error) {
+func mapModel(routine DbRoutine, functionName string, globalTypeMappings *map[string]mapping, routineMapping *RoutineMapping, config *Config) ([]Property, []JsonModel, error) {
 
 	modelProperties := make([]Property, 0)
+	jsonModels := make([]JsonModel, 0)
 
 	//procedures in pg don't have return type
 	if routine.FuncType == Procedure || slices.Contains(voidTypes, routine.DataType) {
-		return modelProperties, nil
+		return modelProperties, jsonModels, nil
 	}
 
 	columns := routine.OutParameters
 
 	// If value is simple data type
-	if !slices.Contains(structuredTypes, routine.DataType) {
+	// routines returning enum have no columns and no return, same as before enums were loaded
+	if !slices.Contains(structuredTypes, routine.DataType) || routine.RangeSubtype != "" {
+		dataType := routine.DataType
+
+		// user defined ranges are not structured types
+		if routine.RangeSubtype != "" {
+			dataType = routine.UdtTypeName
+		}
 
 		// if function has return type, it means it return just one value
 		columns = []DbParameter{{
 			OrdinalPosition: 0,
 			Name:            routine.RoutineName,
 			Mode:            OutMode,
-			UDTName:         routine.DataType,
+			UDTName:         dataType,
+			UDTSchema:       routine.UdtTypeScheme,
 			IsNullable:      false,
+			RangeSubtype:    routine.RangeSubtype,
+			IsMultirange:    routine.IsMultirange,
 		}}
 
 	}
@@ -109,7 +149,7 @@ func mapModel(routine DbRoutine, globalTypeMappings *map[string]mapping, routine
 	properties := make([]Property, 0)
 
 	if columns == nil || len(columns) == 0 {
-		return properties, nil
+		return properties, jsonModels, nil
 	}
 
 	// Make sure attributes are in right order
@@ -124,7 +164,7 @@ func mapModel(routine DbRoutine, globalTypeMappings *map[string]mapping, routine
 		shouldSelect, columnMapping, err := getColumnMapping(column, routineMapping, globalTypeMappings, config)
 
 		if err != nil {
-			return nil, fmt.Errorf("getting effective mapping of %s: %s", column.Name, err)
+			return nil, nil, fmt.Errorf("getting effective mapping of %s: %s", column.Name, err)
 		}
 
 		if !shouldSelect {
@@ -132,6 +172,14 @@ func mapModel(routine DbRoutine, globalTypeMappings *map[string]mapping, routine
 			continue
 		}
 
+		columnJsonModels, err := applyJsonSchema(columnMapping, functionName, globalTypeMappings, config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("mapping json schema of %s: %s", column.Name, err)
+		}
+		jsonModels = append(jsonModels, columnJsonModels...)
+
+		isRange, isMultirange := getRangeKind(column)
+
 		property := Property{
 			DbColumnName:   column.Name,
 			DbColumnType:   column.UDTName,
@@ -139,22 +187,31 @@ func mapModel(routine DbRoutine, globalTypeMappings *map[string]mapping, routine
 			PropertyType:   columnMapping.typeMapping.mappedType,
 			Position:       column.OrdinalPosition - positionOffset,
 			MapperFunction: columnMapping.typeMapping.mappedFunction,
+			TypeParameter:  columnMapping.typeParameter,
+			fallbackTypes:  columnMapping.typeMapping.fallbackTypes,
+			JsonModel:      columnMapping.jsonModel,
+			IsRange:        isRange,
+			IsMultirange:   isMultirange,
+			RangeSubtype:   column.RangeSubtype,
 			Nullable:       columnMapping.isNullable,
 			Optional:       columnMapping.isOptional,
+			Tags:           columnMapping.tags,
+			Extra:          columnMapping.extra,
 		}
 
 		properties = append(properties, property)
 	}
 
-	return properties, nil
+	return properties, jsonModels, nil
 }
 
-func mapParameters(attributes []DbParameter, typeMappings *map[string]mapping, routineMapping *RoutineMapping, config *Config) ([]Property, error) {
+func mapParameters(attributes []DbParameter, functionName string, typeMappings *map[string]mapping, routineMapping *RoutineMapping, config *Config) ([]Property, []JsonModel, error) {
 
 	properties := make([]Property, len(attributes))
+	jsonModels := make([]JsonModel, 0)
 
 	if attributes == nil || len(attributes) == 0 {
-		return properties, nil
+		return properties, jsonModels, nil
 	}
 
 	// Make sure attributes are in right order
@@ -169,8 +226,16 @@ func mapParameters(attributes []DbParameter, typeMappings *map[string]mapping, r
 	for i, parameter := range attributes {
 		effectiveMapping, err := getParamMapping(parameter, routineMapping, typeMappings, config)
 		if err != nil {
-			return nil, fmt.Errorf("processing parameter %s: %s", parameter.Name, err)
+			return nil, nil, fmt.Errorf("processing parameter %s: %s", parameter.Name, err)
+		}
+
+		parameterJsonModels, err := applyJsonSchema(effectiveMapping, functionName, typeMappings, config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("mapping json schema of parameter %s: %s", parameter.Name, err)
 		}
+		jsonModels = append(jsonModels, parameterJsonModels...)
+
+		isRange, isMultirange := getRangeKind(parameter)
 
 		property := &Property{
 			DbColumnName:   parameter.Name,
@@ -179,14 +244,22 @@ func mapParameters(attributes []DbParameter, typeMappings *map[string]mapping, r
 			PropertyType:   effectiveMapping.typeMapping.mappedType,
 			Position:       parameter.OrdinalPosition - positionOffset,
 			MapperFunction: "",
+			TypeParameter:  effectiveMapping.typeParameter,
+			fallbackTypes:  effectiveMapping.typeMapping.fallbackTypes,
+			JsonModel:      effectiveMapping.jsonModel,
+			IsRange:        isRange,
+			IsMultirange:   isMultirange,
+			RangeSubtype:   parameter.RangeSubtype,
 			Nullable:       effectiveMapping.isNullable,
 			Optional:       effectiveMapping.isOptional,
+			Tags:           effectiveMapping.tags,
+			Extra:          effectiveMapping.extra,
 		}
 
 		properties[i] = *property
 	}
 
-	return properties, nil
+	return properties, jsonModels, nil
 }
 
 func getRoutineMapping(routine DbRoutine, schemaConfigs map[string]SchemaConfig) RoutineMapping {
@@ -195,17 +268,19 @@ func getRoutineMapping(routine DbRoutine, schemaConfigs map[string]SchemaConfig)
 		// this should never happen
 		panic("trying ty get function mapping for function in schema that is not defined. This should never happen, because function should have been fitered out")
 	}
+	patternMappings := getPatternMappings(routine.RoutineName, &schemaConfig)
+
 	routineMapping, found := schemaConfig.Functions[routine.RoutineNameWithParams]
 	if found {
-		return routineMapping
+		return applyPatternMappings(routineMapping, patternMappings)
 	}
 
 	routineMapping, found = schemaConfig.Functions[routine.RoutineName]
 	if found {
-		return routineMapping
+		return applyPatternMappings(routineMapping, patternMappings)
 	}
 
-	return emptyMapping
+	return applyPatternMappings(emptyMapping, patternMappings)
 }
 
 func getColumnMapping(param DbParameter, routineMapping *RoutineMapping, globalMappings *map[string]mapping, config *Config) (bool, *effectiveParamMapping, error) {
@@ -215,8 +290,10 @@ func getColumnMapping(param DbParameter, routineMapping *RoutineMapping, globalM
 
 	name := common2.ToPascalCase(param.Name)
 	isNullable := param.IsNullable
+	typeParameter := ""
 	var typeMapping *mapping = nil
 	var err error = nil
+	var jsonSchema interface{} = nil
 
 	explicitMapping, hasExplicitParamMapping := routineMapping.Model[param.Name]
 	if !hasExplicitParamMapping && routineMapping.SelectOnlySpecified {
@@ -236,8 +313,12 @@ func getColumnMapping(param DbParameter, routineMapping *RoutineMapping, globalM
 			isNullable = explicitMapping.IsNullable.Bool
 		}
 
+		if explicitMapping.JsonSchema != nil {
+			jsonSchema = explicitMapping.JsonSchema
+		}
+
 		if explicitMapping.MappedType != "" {
-			typeMapping, err = handleTypeMappingOverride(explicitMapping.MappedType, explicitMapping.MappingFunction, config)
+			typeMapping, err = handleTypeMappingOverride(explicitMapping.MappedType, explicitMapping.MappingFunction, param.UDTName, config)
 			if err != nil {
 				return false, nil, err
 			}
@@ -245,19 +326,31 @@ func getColumnMapping(param DbParameter, routineMapping *RoutineMapping, globalM
 		}
 	}
 
+	// configuration wins over column comment
+	if jsonSchema == nil && typeMapping == nil {
+		jsonSchema, err = parseJsonSchemaDirective(param.Comment)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
 	if typeMapping == nil {
-		typeMapping, err = getTypeMapping(param.UDTName, globalMappings)
+		typeMapping, typeParameter, err = resolveTypeMapping(param, routineMapping, globalMappings)
 		if err != nil {
 			return false, nil, err
 		}
 	}
 
 	return true, &effectiveParamMapping{
-		name:        name,
-		typeMapping: *typeMapping,
-		isNullable:  isNullable,
+		name:          name,
+		typeMapping:   *typeMapping,
+		typeParameter: typeParameter,
+		jsonSchema:    jsonSchema,
+		isNullable:    isNullable,
 		// no column has to be selected => column is always optional
 		isOptional: true,
+		tags:       getTags(explicitMapping.Tags),
+		extra:      getExtra(explicitMapping.Extra),
 	}, nil
 
 }
@@ -266,6 +359,8 @@ func getParamMapping(param DbParameter, routineMapping *RoutineMapping, globalMa
 	name := param.Name
 	isNullable := param.IsNullable
 	isOptional := param.IsOptional
+	typeParameter := ""
+	var jsonSchema interface{} = nil
 	var typeMapping *mapping = nil
 	var err error = nil
 
@@ -283,8 +378,10 @@ func getParamMapping(param DbParameter, routineMapping *RoutineMapping, globalMa
 			isOptional = explicitMapping.IsOptional.Bool
 		}
 
+		jsonSchema = explicitMapping.JsonSchema
+
 		if explicitMapping.MappedType != "" {
-			typeMapping, err = handleTypeMappingOverride(explicitMapping.MappedType, "NO MAPPING FUNCTION FOR PARAMS", config)
+			typeMapping, err = handleTypeMappingOverride(explicitMapping.MappedType, "NO MAPPING FUNCTION FOR PARAMS", param.UDTName, config)
 			if err != nil {
 				return nil, err
 			}
@@ -293,21 +390,47 @@ func getParamMapping(param DbParameter, routineMapping *RoutineMapping, globalMa
 	}
 
 	if typeMapping == nil {
-		typeMapping, err = getTypeMapping(param.UDTName, globalMappings)
+		typeMapping, typeParameter, err = resolveTypeMapping(param, routineMapping, globalMappings)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	return &effectiveParamMapping{
-		name:        name,
-		typeMapping: *typeMapping,
-		isNullable:  isNullable,
-		isOptional:  isOptional,
+		name:          name,
+		typeMapping:   *typeMapping,
+		typeParameter: typeParameter,
+		jsonSchema:    jsonSchema,
+		isNullable:    isNullable,
+		isOptional:    isOptional,
+		tags:          getTags(explicitMapping.Tags),
+		extra:         getExtra(explicitMapping.Extra),
 	}, nil
 
 }
 
+// applyJsonSchema maps type to model generated from json schema, if column or parameter has any
+func applyJsonSchema(effectiveMapping *effectiveParamMapping, functionName string, globalMappings *map[string]mapping, config *Config) ([]JsonModel, error) {
+	if effectiveMapping.jsonSchema == nil {
+		return nil, nil
+	}
+
+	schema, err := loadJsonSchema(effectiveMapping.jsonSchema, config)
+	if err != nil {
+		return nil, err
+	}
+
+	typeMapping, rootModel, jsonModels, err := mapJsonSchema(schema, functionName+common2.ToPascalCase(effectiveMapping.name), globalMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveMapping.typeMapping = *typeMapping
+	effectiveMapping.jsonModel = rootModel
+
+	return jsonModels, nil
+}
+
 func getFunctionName(dbFunctionName string, schema string, mappedName string) string {
 	if mappedName != "" {
 		return mappedName
@@ -330,6 +453,50 @@ func getProcessorName(functionName string) string {
 	return common2.ToPascalCase(functionName) + "Processor"
 }
 
+// resolveTypeMapping gets mapping for database type, polymorphic pseudo-types are mapped to type parameter
+// unless the type parameter is pinned to concrete type in routine mapping
+func resolveTypeMapping(param DbParameter, routineMapping *RoutineMapping, globalTypesMappings *map[string]mapping) (*mapping, string, error) {
+	dbDataType := param.UDTName
+
+	family, isPolymorphic := getPolymorphicFamily(dbDataType)
+	if !isPolymorphic {
+		typeMapping, err := getConcreteTypeMapping(param, globalTypesMappings)
+		return typeMapping, "", err
+	}
+
+	concreteType, isPinned := getPinnedType(family.typeParameter, routineMapping)
+	if isPinned {
+		pinnedParam, err := family.pinParameter(param, concreteType)
+		if err != nil {
+			return nil, "", err
+		}
+
+		typeMapping, err := getConcreteTypeMapping(pinnedParam, globalTypesMappings)
+		return typeMapping, "", err
+	}
+
+	var typeMapping *mapping
+	var err error
+
+	_, specificMappingExists := (*globalTypesMappings)[dbDataType]
+	if !specificMappingExists && slices.Contains(family.elementTypes, dbDataType) {
+		// element can be used as type parameter directly
+		typeMapping = &mapping{mappedType: typePlaceholder}
+	} else {
+		typeMapping, err = getTypeMapping(dbDataType, globalTypesMappings)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// fallback or mapping without placeholder doesn't use type parameter, so there is nothing to bind it to
+	if !strings.Contains(typeMapping.mappedType, typePlaceholder) {
+		return typeMapping, "", nil
+	}
+
+	return applyTypePlaceholder(*typeMapping, family.typeParameter), family.typeParameter, nil
+}
+
 // getTypeMapping if explicit mapping doesnt exist, try fallback
 func getTypeMapping(dbDataType string, globalTypesMappings *map[string]mapping) (*mapping, error) {
 	val, specificMappingExists := (*globalTypesMappings)[dbDataType]
@@ -344,6 +511,7 @@ func getTypeMapping(dbDataType string, globalTypesMappings *map[string]mapping)
 
 		common2.LogDebug("Using fallback value %+v for type %s", fallbackVal, dbDataType)
 
+		fallbackVal.fallbackTypes = []string{dbDataType}
 		return &fallbackVal, nil
 	}
 
@@ -351,7 +519,7 @@ func getTypeMapping(dbDataType string, globalTypesMappings *map[string]mapping)
 }
 
 // handleTypeMappingOverride used when parsing model and parameters when mappedType is set
-func handleTypeMappingOverride(typeOverride string, mappingFunctionOverride string, config *Config) (*mapping, error) {
+func handleTypeMappingOverride(typeOverride string, mappingFunctionOverride string, dbDataType string, config *Config) (*mapping, error) {
 	if mappingFunctionOverride != "" {
 		return &mapping{
 			mappedFunction: mappingFunctionOverride,
@@ -359,8 +527,11 @@ func handleTypeMappingOverride(typeOverride string, mappingFunctionOverride stri
 		}, nil
 	}
 
-	// get mapping function
-	for _, typeMapping := range config.Mappings {
+	presetMappings := config.Mappings[:config.presetMappingCount]
+	projectMappings := config.Mappings[config.presetMappingCount:]
+
+	// get mapping function, first project mapping of the type is used
+	for _, typeMapping := range projectMappings {
 		if typeMapping.MappedType == typeOverride {
 			return &mapping{
 				mappedFunction: typeMapping.MappingFunction,
@@ -369,6 +540,24 @@ func handleTypeMappingOverride(typeOverride string, mappingFunctionOverride stri
 		}
 	}
 
+	// presets map more database types to one type with different functions (e.g. date and timestamp to time.Time),
+	// so mapping of the database type itself is preferred, later presets win same as in getTypeMappings
+	for _, matchDbType := range []bool{true, false} {
+		for i := len(presetMappings) - 1; i >= 0; i-- {
+			typeMapping := presetMappings[i]
+			if typeMapping.MappedType != typeOverride {
+				continue
+			}
+
+			if !matchDbType || slices.Contains(typeMapping.DatabaseTypes, dbDataType) {
+				return &mapping{
+					mappedFunction: typeMapping.MappingFunction,
+					mappedType:     typeOverride,
+				}, nil
+			}
+		}
+	}
+
 	// no mapping function is set and no mapping exist for type given
 	return nil, fmt.Errorf("mapped type overriden to %s, but no mapping functions specified and mapping function for override type doenst exist in mappings", typeOverride)
 }
@@ -0,0 +1,54 @@
+package dbGen
+
+import "testing"
+
+func TestHandleTypeMappingOverride(t *testing.T) {
+	presetMappings := []Mapping{
+		{DatabaseTypes: []string{"date"}, MappedType: "time.Time", MappingFunction: "GetDate"},
+		{DatabaseTypes: []string{"timestamp"}, MappedType: "time.Time", MappingFunction: "GetTimestamp"},
+		{DatabaseTypes: []string{"text"}, MappedType: "string", MappingFunction: "GetFirstPresetString"},
+		{DatabaseTypes: []string{"varchar"}, MappedType: "string", MappingFunction: "GetLastPresetString"},
+	}
+
+	projectMappings := []Mapping{
+		{DatabaseTypes: []string{"int4"}, MappedType: "int", MappingFunction: "GetFirstInt"},
+		{DatabaseTypes: []string{"int8"}, MappedType: "int", MappingFunction: "GetLastInt"},
+	}
+
+	tests := []struct {
+		name             string
+		mappings         []Mapping
+		presetCount      int
+		typeOverride     string
+		functionOverride string
+		dbDataType       string
+		want             string
+	}{
+		{"function override", projectMappings, 0, "int", "GetCustom", "int8", "GetCustom"},
+		{"first project mapping", projectMappings, 0, "int", "", "int8", "GetFirstInt"},
+		{"preset of database type", presetMappings, len(presetMappings), "time.Time", "", "date", "GetDate"},
+		{"preset of other database type", presetMappings, len(presetMappings), "time.Time", "", "timestamp", "GetTimestamp"},
+		{"last preset without database type", presetMappings, len(presetMappings), "string", "", "uuid", "GetLastPresetString"},
+		{"project before preset", append(presetMappings, projectMappings...), len(presetMappings), "int", "", "int8", "GetFirstInt"},
+		{"preset when project doesn't map type", append(presetMappings, projectMappings...), len(presetMappings), "time.Time", "", "timestamp", "GetTimestamp"},
+	}
+
+	for _, test := range tests {
+		config := &Config{Mappings: test.mappings, presetMappingCount: test.presetCount}
+
+		got, err := handleTypeMappingOverride(test.typeOverride, test.functionOverride, test.dbDataType, config)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if got.mappedFunction != test.want || got.mappedType != test.typeOverride {
+			t.Errorf("%s: got %s %s, want %s %s", test.name, got.mappedType, got.mappedFunction, test.typeOverride, test.want)
+		}
+	}
+
+	_, err := handleTypeMappingOverride("unknown", "", "int4", &Config{Mappings: projectMappings})
+	if err == nil {
+		t.Error("override to type without mapping didn't fail")
+	}
+}
@@ -0,0 +1,79 @@
+package dbGen
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Mapping presets are embedded in executable, so they are available offline and stay the same for given version
+
+//go:embed mappingPresets/*.json
+var mappingPresetsFs embed.FS
+
+const mappingPresetsFolder = "mappingPresets"
+
+type mappingPreset struct {
+	Mappings []Mapping `json:"Mappings"`
+}
+
+// GetMappingPresetNames returns names of all built-in mapping presets
+func GetMappingPresetNames() ([]string, error) {
+	entries, err := mappingPresetsFs.ReadDir(mappingPresetsFolder)
+	if err != nil {
+		return nil, fmt.Errorf("reading mapping presets: %s", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetMappingPresetContent returns preset exactly as it is embedded, so it can be copied to configuration
+func GetMappingPresetContent(name string) ([]byte, error) {
+	content, err := mappingPresetsFs.ReadFile(path.Join(mappingPresetsFolder, name+".json"))
+	if err != nil {
+		names, _ := GetMappingPresetNames()
+		return nil, fmt.Errorf("mapping preset '%s' doesn't exist, available presets: %s", name, strings.Join(names, ", "))
+	}
+
+	return content, nil
+}
+
+func loadMappingPreset(name string) ([]Mapping, error) {
+	content, err := GetMappingPresetContent(name)
+	if err != nil {
+		return nil, err
+	}
+
+	preset := new(mappingPreset)
+	err = json.Unmarshal(content, preset)
+	if err != nil {
+		return nil, fmt.Errorf("parsing mapping preset %s: %s", name, err)
+	}
+
+	return preset.Mappings, nil
+}
+
+// applyMappingPresets puts preset mappings before project mappings, so project mappings win (last mapping is used)
+func applyMappingPresets(presetNames []string, projectMappings []Mapping) ([]Mapping, error) {
+	mappings := make([]Mapping, 0)
+
+	for _, presetName := range presetNames {
+		presetMappings, err := loadMappingPreset(presetName)
+		if err != nil {
+			return nil, err
+		}
+
+		mappings = append(mappings, presetMappings...)
+	}
+
+	return append(mappings, projectMappings...), nil
+}
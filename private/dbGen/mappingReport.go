@@ -0,0 +1,74 @@
+package dbGen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FallbackMappingReport lists database types that had no mapping and were mapped using fallback (*)
+type FallbackMappingReport struct {
+	// usages of type, e.g. public.my_function column id
+	usages map[string][]string
+}
+
+func GetFallbackMappingReport(routines []Routine) *FallbackMappingReport {
+	report := &FallbackMappingReport{usages: make(map[string][]string)}
+
+	for _, routine := range routines {
+		for _, parameter := range routine.Parameters {
+			report.add(parameter.fallbackTypes, fmt.Sprintf("%s parameter %s", routine.DbFullFunctionName, parameter.DbColumnName))
+		}
+
+		for _, column := range routine.ReturnProperties {
+			report.add(column.fallbackTypes, fmt.Sprintf("%s column %s", routine.DbFullFunctionName, column.DbColumnName))
+		}
+	}
+
+	return report
+}
+
+func (report *FallbackMappingReport) add(fallbackTypes []string, usage string) {
+	for _, fallbackType := range fallbackTypes {
+		report.usages[fallbackType] = append(report.usages[fallbackType], usage)
+	}
+}
+
+func (report *FallbackMappingReport) IsEmpty() bool {
+	return len(report.usages) == 0
+}
+
+// Summary returns one line with every type and number of its usages
+func (report *FallbackMappingReport) Summary() string {
+	parts := make([]string, 0, len(report.usages))
+	for _, dbType := range report.sortedTypes() {
+		parts = append(parts, fmt.Sprintf("%s (%dx)", dbType, len(report.usages[dbType])))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Details returns every type with list of routines and columns using it
+func (report *FallbackMappingReport) Details() string {
+	var out strings.Builder
+
+	for _, dbType := range report.sortedTypes() {
+		out.WriteString(fmt.Sprintf(" - %s:\n", dbType))
+
+		for _, usage := range report.usages[dbType] {
+			out.WriteString(fmt.Sprintf("\t %s\n", usage))
+		}
+	}
+
+	return out.String()
+}
+
+func (report *FallbackMappingReport) sortedTypes() []string {
+	types := make([]string, 0, len(report.usages))
+	for dbType := range report.usages {
+		types = append(types, dbType)
+	}
+
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,137 @@
+package dbGen
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Tags and Extra are free-form metadata of routines, columns and parameters, db-gen only passes them to templates.
+// Functions keys containing glob characters (admin_*) are pattern entries, they set Generate, Tags and Extra
+// of all matching routines, explicit entry of the routine is applied on top of them
+
+const patternCharacters = "*?["
+
+func isFunctionPattern(key string) bool {
+	return strings.ContainsAny(key, patternCharacters)
+}
+
+// validatePatternMapping checks that pattern entry is valid glob and sets only values that can be shared by routines
+func validatePatternMapping(pattern string, routineMapping *RoutineMapping) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern '%s': %s", pattern, err)
+	}
+
+	if routineMapping.MappedName != "" ||
+		routineMapping.DontRetrieveValues ||
+		routineMapping.SelectOnlySpecified ||
+		len(routineMapping.TypeParameters) > 0 ||
+		len(routineMapping.Model) > 0 ||
+		len(routineMapping.Parameters) > 0 {
+		return fmt.Errorf("pattern '%s' can only set Generate, Tags and Extra", pattern)
+	}
+
+	return nil
+}
+
+// getPatternMappings returns pattern entries matching routine name, sorted by pattern so merging is deterministic
+func getPatternMappings(routineName string, schemaConfig *SchemaConfig) []RoutineMapping {
+	patterns := make([]string, 0)
+	for key := range schemaConfig.Functions {
+		if !isFunctionPattern(key) {
+			continue
+		}
+
+		// patterns are validated when configuration is loaded
+		if matches, _ := path.Match(key, routineName); matches {
+			patterns = append(patterns, key)
+		}
+	}
+
+	sort.Strings(patterns)
+
+	mappings := make([]RoutineMapping, len(patterns))
+	for i, pattern := range patterns {
+		mappings[i] = schemaConfig.Functions[pattern]
+	}
+
+	return mappings
+}
+
+// applyPatternMappings merges tags and extra values of matching pattern entries to routine mapping
+func applyPatternMappings(routineMapping RoutineMapping, patternMappings []RoutineMapping) RoutineMapping {
+	tags := make([]string, 0)
+	extra := make(map[string]interface{})
+
+	for _, patternMapping := range append(patternMappings, routineMapping) {
+		tags = appendTags(tags, patternMapping.Tags)
+		maps.Copy(extra, patternMapping.Extra)
+	}
+
+	routineMapping.Tags = tags
+	routineMapping.Extra = extra
+
+	return routineMapping
+}
+
+// getTags returns copy of tags without duplicates, result is never nil, so templates can always range over it
+func getTags(tags []string) []string {
+	return appendTags(make([]string, 0, len(tags)), tags)
+}
+
+func appendTags(tags []string, newTags []string) []string {
+	for _, tag := range newTags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+func getExtra(extra map[string]interface{}) map[string]interface{} {
+	if extra == nil {
+		return make(map[string]interface{})
+	}
+
+	return maps.Clone(extra)
+}
+
+// restoreExtras replaces extra values with raw values, because viper lowercases keys
+func restoreExtras(config *Config, rawPath ...interface{}) {
+	restore := func(extra map[string]interface{}, mappingPath ...interface{}) map[string]interface{} {
+		if extra == nil {
+			return nil
+		}
+
+		rawExtra, exists := getRawConfigValue(append(append(slices.Clone(rawPath), mappingPath...), "Extra")...)
+		if rawMap, isMap := rawExtra.(map[string]interface{}); exists && isMap {
+			return rawMap
+		}
+
+		return extra
+	}
+
+	for i, schemaConfig := range config.Generate {
+		for functionName, routineMapping := range schemaConfig.Functions {
+			functionPath := []interface{}{"Generate", i, "Functions", functionName}
+
+			routineMapping.Extra = restore(routineMapping.Extra, functionPath...)
+
+			for columnName, columnMapping := range routineMapping.Model {
+				columnMapping.Extra = restore(columnMapping.Extra, append(functionPath, "Model", columnName)...)
+				routineMapping.Model[columnName] = columnMapping
+			}
+
+			for paramName, paramMapping := range routineMapping.Parameters {
+				paramMapping.Extra = restore(paramMapping.Extra, append(functionPath, "Parameters", paramName)...)
+				routineMapping.Parameters[paramName] = paramMapping
+			}
+
+			schemaConfig.Functions[functionName] = routineMapping
+		}
+	}
+}
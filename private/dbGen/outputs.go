@@ -0,0 +1,294 @@
+package dbGen
+
+import (
+	"fmt"
+	"github.com/keenmate/db-gen/private/version"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Every output renders one template for each item of its scope (routine, enum...).
+// Filter and Path are small templates evaluated with the item, e.g. "models/{{.ModelName}}.cs"
+
+const (
+	ScopeGlobal     = "global"
+	ScopeSchema     = "schema"
+	ScopeRoutine    = "routine"
+	ScopeModel      = "model" // routines with return value
+	ScopeEnum       = "enum"
+	ScopeCustomType = "customType"
+	ScopeJsonModel  = "jsonModel"
+)
+
+var validScopes = []string{ScopeGlobal, ScopeSchema, ScopeRoutine, ScopeModel, ScopeEnum, ScopeCustomType, ScopeJsonModel}
+
+// filter output values that exclude item
+var falseFilterValues = []string{"", "false", "0", "<no value>"}
+
+type OutputConfig struct {
+	Name     string `mapstructure:"Name"`
+	Template string `mapstructure:"Template"`
+	Scope    string `mapstructure:"Scope"`
+	Filter   string `mapstructure:"Filter"`   // item is generated only when filter renders to true
+	Path     string `mapstructure:"Path"`     // relative to OutputFolder
+	FileCase string `mapstructure:"FileCase"` // case of file name, if empty, name is kept as rendered
+	Override string // file from TemplateOverrides overriding template of pack
+}
+
+type outputItem struct {
+	// data used to render Filter and Path
+	pathData     interface{}
+	templateData interface{}
+	names        []string // identifiers of item, used to select item to render
+}
+
+// getLegacyOutputs converts DbContextTemplate, ModelTemplate, ProcessorTemplate and JsonModelTemplate to outputs
+func getLegacyOutputs(config *Config) []OutputConfig {
+	outputs := make([]OutputConfig, 0)
+	extension := config.GeneratedFileExtension
+
+	if config.DbContextTemplate != "" {
+		outputs = append(outputs, OutputConfig{
+			Name:     "dbcontext",
+			Template: config.DbContextTemplate,
+			Scope:    ScopeGlobal,
+			Path:     "DbContext" + extension,
+			FileCase: config.GeneratedFileCase,
+		})
+	}
+
+	if config.GenerateModels {
+		outputs = append(outputs, OutputConfig{
+			Name:     "models",
+			Template: config.ModelTemplate,
+			Scope:    ScopeModel,
+			Path:     filepath.Join(config.ModelsFolderName, "{{.ModelName}}"+extension),
+			FileCase: config.GeneratedFileCase,
+		})
+	}
+
+	if config.GenerateProcessors {
+		filter := "{{.HasReturn}}"
+		if config.GenerateProcessorsForVoidReturns {
+			filter = ""
+		}
+
+		outputs = append(outputs, OutputConfig{
+			Name:     "processors",
+			Template: config.ProcessorTemplate,
+			Scope:    ScopeRoutine,
+			Filter:   filter,
+			Path:     filepath.Join(config.ProcessorsFolderName, "{{.ProcessorName}}"+extension),
+			FileCase: config.GeneratedFileCase,
+		})
+	}
+
+	if config.JsonModelTemplate != "" {
+		outputs = append(outputs, OutputConfig{
+			Name:     "jsonModels",
+			Template: config.JsonModelTemplate,
+			Scope:    ScopeJsonModel,
+			Path:     filepath.Join(config.ModelsFolderName, "{{.Name}}"+extension),
+			FileCase: config.GeneratedFileCase,
+		})
+	}
+
+	return outputs
+}
+
+func validateOutputs(outputs []OutputConfig) error {
+	names := make(map[string]bool)
+
+	for _, output := range outputs {
+		if output.Template == "" {
+			return fmt.Errorf("output %s has no template", output.Name)
+		}
+
+		if !slices.Contains(validScopes, output.Scope) {
+			return fmt.Errorf("output %s has invalid scope '%s', valid scopes are %s", output.Name, output.Scope, strings.Join(validScopes, ", "))
+		}
+
+		if output.Path == "" {
+			return fmt.Errorf("output %s has no path", output.Name)
+		}
+
+		if output.FileCase != "" && !slices.Contains(ValidCaseNormalized, output.FileCase) {
+			return fmt.Errorf("output %s has invalid file case '%s'", output.Name, output.FileCase)
+		}
+
+		if names[output.Name] {
+			return fmt.Errorf("output name %s is used multiple times", output.Name)
+		}
+		names[output.Name] = true
+	}
+
+	return nil
+}
+
+// normalizeOutputs returns outputs with resolved template paths and default names
+func normalizeOutputs(configOutputs []OutputConfig, pathBase string) []OutputConfig {
+	// outputs can be shared by multiple targets, so they are copied
+	outputs := append([]OutputConfig{}, configOutputs...)
+
+	for i, output := range outputs {
+		outputs[i].Template = joinIfRelative(pathBase, output.Template)
+		outputs[i].FileCase = strings.ToLower(output.FileCase)
+
+		if output.Name == "" {
+			outputs[i].Name = strings.TrimSuffix(filepath.Base(output.Template), filepath.Ext(output.Template))
+		}
+	}
+
+	return outputs
+}
+
+func getOutputItems(output OutputConfig, routines []Routine, buildInfo *version.BuildInformation, config *Config) ([]outputItem, error) {
+	items := make([]outputItem, 0)
+
+	switch output.Scope {
+	case ScopeGlobal:
+		jsonModels, err := collectJsonModels(routines)
+		if err != nil {
+			return nil, err
+		}
+
+		data := &DbContextData{
+			Config:      config,
+			Functions:   routines,
+			JsonModels:  jsonModels,
+			Enums:       collectEnums(routines),
+			CustomTypes: collectCustomTypes(routines),
+			Vars:        config.Variables,
+			BuildInfo:   buildInfo,
+		}
+		items = append(items, outputItem{pathData: data, templateData: data})
+	case ScopeSchema:
+		for _, schema := range getSchemas(config) {
+			schemaRoutines := make([]Routine, 0)
+			for _, routine := range routines {
+				if routine.Schema == schema {
+					schemaRoutines = append(schemaRoutines, routine)
+				}
+			}
+
+			if len(schemaRoutines) == 0 {
+				continue
+			}
+
+			jsonModels, err := collectJsonModels(schemaRoutines)
+			if err != nil {
+				return nil, err
+			}
+
+			data := &SchemaTemplateData{
+				Config:      config,
+				Schema:      schema,
+				Functions:   schemaRoutines,
+				JsonModels:  jsonModels,
+				Enums:       collectEnums(schemaRoutines),
+				CustomTypes: collectCustomTypes(schemaRoutines),
+				Vars:        config.Variables,
+				BuildInfo:   buildInfo,
+			}
+			items = append(items, outputItem{pathData: data, templateData: data, names: []string{schema}})
+		}
+	case ScopeRoutine, ScopeModel:
+		for _, routine := range routines {
+			if output.Scope == ScopeModel && !routine.HasReturn {
+				continue
+			}
+
+			var data interface{} = &ProcessorTemplateData{Config: config, Routine: routine, Vars: config.Variables, BuildInfo: buildInfo}
+			if output.Scope == ScopeModel {
+				data = &ModelTemplateData{Config: config, Routine: routine, Vars: config.Variables, BuildInfo: buildInfo}
+			}
+
+			items = append(items, outputItem{
+				pathData:     routine,
+				templateData: data,
+				names:        []string{routine.DbFullFunctionName, routine.DbFunctionName, routine.FunctionName},
+			})
+		}
+	case ScopeEnum:
+		for _, enum := range collectEnums(routines) {
+			data := &EnumTemplateData{Config: config, Enum: enum, Vars: config.Variables, BuildInfo: buildInfo}
+			items = append(items, outputItem{pathData: enum, templateData: data, names: getTypeNames(enum.Schema, enum.DbTypeName, enum.Name)})
+		}
+	case ScopeCustomType:
+		for _, customType := range collectCustomTypes(routines) {
+			data := &CustomTypeTemplateData{Config: config, CustomType: customType, Vars: config.Variables, BuildInfo: buildInfo}
+			items = append(items, outputItem{pathData: customType, templateData: data, names: getTypeNames(customType.Schema, customType.DbTypeName, customType.Name)})
+		}
+	case ScopeJsonModel:
+		jsonModels, err := collectJsonModels(routines)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, jsonModel := range jsonModels {
+			data := &JsonModelTemplateData{Config: config, Model: jsonModel, Vars: config.Variables, BuildInfo: buildInfo}
+			items = append(items, outputItem{pathData: jsonModel, templateData: data, names: []string{jsonModel.Name}})
+		}
+	default:
+		return nil, fmt.Errorf("unknown scope %s", output.Scope)
+	}
+
+	return items, nil
+}
+
+func getTypeNames(schema string, dbTypeName string, name string) []string {
+	return []string{schema + "." + dbTypeName, dbTypeName, name}
+}
+
+func parseInlineTemplate(name string, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).
+		Funcs(getTemplateFunctions()).
+		Parse(text)
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %s", name, err)
+	}
+
+	return tmpl, nil
+}
+
+func renderInlineTemplate(tmpl *template.Template, data interface{}) (string, error) {
+	var out strings.Builder
+
+	err := tmpl.Execute(&out, data)
+	if err != nil {
+		return "", fmt.Errorf("rendering %s template: %s", tmpl.Name(), err)
+	}
+
+	return strings.TrimSpace(out.String()), nil
+}
+
+func isFilterTrue(value string) bool {
+	return !slices.Contains(falseFilterValues, strings.ToLower(value))
+}
+
+// getOutputPath renders path and changes case of file name, returned path is relative to output folder
+func getOutputPath(pathTemplate *template.Template, data interface{}, fileCase string) (string, error) {
+	rendered, err := renderInlineTemplate(pathTemplate, data)
+	if err != nil {
+		return "", err
+	}
+
+	relPath := filepath.Clean(filepath.FromSlash(rendered))
+	if !filepath.IsLocal(relPath) {
+		return "", fmt.Errorf("path %s is not inside output folder", rendered)
+	}
+
+	// empty path or path of folder itself, e.g. "models/.."
+	if relPath == "." {
+		return "", fmt.Errorf("path '%s' is not a file path", rendered)
+	}
+
+	if fileCase != "" {
+		relPath = filepath.Join(filepath.Dir(relPath), changeCase(filepath.Base(relPath), fileCase))
+	}
+
+	return relPath, nil
+}
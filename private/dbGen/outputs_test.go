@@ -0,0 +1,299 @@
+package dbGen
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func getOutputsTestRoutines() []Routine {
+	status := Enum{Name: "Status", Schema: "public", DbTypeName: "status", Values: []string{"active"}}
+	address := CustomType{Name: "Address", Schema: "public", DbTypeName: "address"}
+	rows := JsonModel{Name: "Rows"}
+
+	return []Routine{
+		{
+			FunctionName:       "GetUsers",
+			DbFullFunctionName: "public.get_users",
+			DbFunctionName:     "get_users",
+			Schema:             "public",
+			HasReturn:          true,
+			Enums:              []Enum{status},
+			JsonModels:         []JsonModel{rows},
+			Tags:               []string{"api"},
+		},
+		{
+			FunctionName:       "DeleteUser",
+			DbFullFunctionName: "public.delete_user",
+			DbFunctionName:     "delete_user",
+			Schema:             "public",
+			Enums:              []Enum{status},
+			Tags:               []string{},
+		},
+		{
+			FunctionName:       "GetAddress",
+			DbFullFunctionName: "admin.get_address",
+			DbFunctionName:     "get_address",
+			Schema:             "admin",
+			HasReturn:          true,
+			CustomType:         &address,
+			JsonModels:         []JsonModel{rows},
+			Tags:               []string{},
+		},
+	}
+}
+
+func TestGetOutputItems(t *testing.T) {
+	config := &Config{
+		Generate: []SchemaConfig{{Schema: "public"}, {Schema: "empty"}, {Schema: "admin"}, {Schema: "public"}},
+	}
+
+	tests := []struct {
+		scope string
+		want  [][]string
+	}{
+		{ScopeGlobal, [][]string{nil}},
+		// schemas without routines are skipped, order of Generate is kept
+		{ScopeSchema, [][]string{{"public"}, {"admin"}}},
+		{ScopeRoutine, [][]string{
+			{"public.get_users", "get_users", "GetUsers"},
+			{"public.delete_user", "delete_user", "DeleteUser"},
+			{"admin.get_address", "get_address", "GetAddress"},
+		}},
+		// void routines have no model
+		{ScopeModel, [][]string{
+			{"public.get_users", "get_users", "GetUsers"},
+			{"admin.get_address", "get_address", "GetAddress"},
+		}},
+		// distinct enums, custom types and json models
+		{ScopeEnum, [][]string{{"public.status", "status", "Status"}}},
+		{ScopeCustomType, [][]string{{"public.address", "address", "Address"}}},
+		{ScopeJsonModel, [][]string{{"Rows"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scope, func(t *testing.T) {
+			items, err := getOutputItems(OutputConfig{Scope: test.scope}, getOutputsTestRoutines(), nil, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(items) != len(test.want) {
+				t.Fatalf("got %d items, want %d", len(items), len(test.want))
+			}
+
+			for i, item := range items {
+				if !slices.Equal(item.names, test.want[i]) {
+					t.Errorf("item %d: got names %v, want %v", i, item.names, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetOutputItemsData(t *testing.T) {
+	config := &Config{Generate: []SchemaConfig{{Schema: "public"}, {Schema: "admin"}}}
+	routines := getOutputsTestRoutines()
+
+	items, err := getOutputItems(OutputConfig{Scope: ScopeSchema}, routines, nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := items[0].templateData.(*SchemaTemplateData)
+	if len(data.Functions) != 2 || len(data.Enums) != 1 || len(data.JsonModels) != 1 || len(data.CustomTypes) != 0 {
+		t.Errorf("schema public got %d functions, %d enums, %d json models, %d custom types", len(data.Functions), len(data.Enums), len(data.JsonModels), len(data.CustomTypes))
+	}
+
+	// path and filter of routine outputs are rendered with routine, template with template data
+	items, err = getOutputItems(OutputConfig{Scope: ScopeModel}, routines, nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, isRoutine := items[0].pathData.(Routine); !isRoutine {
+		t.Errorf("got path data %T, want Routine", items[0].pathData)
+	}
+	if _, isModel := items[0].templateData.(*ModelTemplateData); !isModel {
+		t.Errorf("got template data %T, want *ModelTemplateData", items[0].templateData)
+	}
+
+	_, err = getOutputItems(OutputConfig{Scope: "table"}, routines, nil, config)
+	if err == nil {
+		t.Error("unknown scope didn't fail")
+	}
+
+	// json models with the same name must have the same properties
+	routines[2].JsonModels = []JsonModel{{Name: "Rows", Properties: []JsonProperty{{JsonName: "id"}}}}
+	_, err = getOutputItems(OutputConfig{Scope: ScopeJsonModel}, routines, nil, config)
+	if err == nil {
+		t.Error("different json models with the same name didn't fail")
+	}
+}
+
+func TestIsFilterTrue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"true", true},
+		{"True", true},
+		{"1", true},
+		{"yes", true},
+		{"public", true},
+		{"", false},
+		{"false", false},
+		{"FALSE", false},
+		{"0", false},
+		{"<no value>", false},
+	}
+
+	for _, test := range tests {
+		if got := isFilterTrue(test.value); got != test.want {
+			t.Errorf("isFilterTrue(%q) = %t, want %t", test.value, got, test.want)
+		}
+	}
+}
+
+func TestFilterEvaluation(t *testing.T) {
+	routines := getOutputsTestRoutines()
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"{{.HasReturn}}", []string{"GetUsers", "GetAddress"}},
+		{"{{not .HasReturn}}", []string{"DeleteUser"}},
+		{`{{eq .Schema "admin"}}`, []string{"GetAddress"}},
+		{`{{hasTag "api" .}}`, []string{"GetUsers"}},
+		{`{{hasPrefix "Get" .FunctionName}}`, []string{"GetUsers", "GetAddress"}},
+		// missing key of map renders <no value>
+		{"{{.Extra.skip}}", []string{}},
+		{"  true\n", []string{"GetUsers", "DeleteUser", "GetAddress"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			filterTemplate, err := parseInlineTemplate("filter", test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0)
+			for _, routine := range routines {
+				value, err := renderInlineTemplate(filterTemplate, routine)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if isFilterTrue(value) {
+					got = append(got, routine.FunctionName)
+				}
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	filterTemplate, err := parseInlineTemplate("filter", "{{.Missing}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = renderInlineTemplate(filterTemplate, routines[0])
+	if err == nil || !strings.Contains(err.Error(), "rendering filter template") {
+		t.Errorf("got error %v, want rendering error", err)
+	}
+}
+
+func TestGetOutputPath(t *testing.T) {
+	routine := Routine{FunctionName: "GetUsers", ModelName: "GetUsersModel", Schema: "public"}
+
+	tests := []struct {
+		path     string
+		fileCase string
+		want     string
+		wantErr  bool
+	}{
+		{path: "models/{{.ModelName}}.cs", want: "models/GetUsersModel.cs"},
+		{path: "{{.Schema}}/{{.FunctionName}}.go", fileCase: "snakecase", want: "public/get_users.go"},
+		{path: "Models/{{.FunctionName}}.ts", fileCase: "camelcase", want: "Models/getUsers.ts"},
+		{path: "./models//{{.ModelName}}.cs", want: "models/GetUsersModel.cs"},
+		{path: "models/../{{.FunctionName}}.cs", want: "GetUsers.cs"},
+		// path must stay inside output folder
+		{path: "../{{.FunctionName}}.cs", wantErr: true},
+		{path: "models/../../{{.FunctionName}}.cs", wantErr: true},
+		{path: "/tmp/{{.FunctionName}}.cs", wantErr: true},
+		// path must be a file path
+		{path: "{{if false}}x{{end}}", wantErr: true},
+		{path: "models/..", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			pathTemplate, err := parseInlineTemplate("path", test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := getOutputPath(pathTemplate, routine, test.fileCase)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %s, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != filepath.FromSlash(test.want) {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateOutputs(t *testing.T) {
+	valid := OutputConfig{Name: "models", Template: "model.gotmpl", Scope: ScopeModel, Path: "{{.ModelName}}.cs"}
+
+	tests := []struct {
+		name    string
+		change  func(output *OutputConfig)
+		wantErr string
+	}{
+		{"valid", func(output *OutputConfig) {}, ""},
+		{"file case", func(output *OutputConfig) { output.FileCase = "snakecase" }, ""},
+		{"no template", func(output *OutputConfig) { output.Template = "" }, "has no template"},
+		{"invalid scope", func(output *OutputConfig) { output.Scope = "table" }, "invalid scope 'table'"},
+		{"no path", func(output *OutputConfig) { output.Path = "" }, "has no path"},
+		{"invalid file case", func(output *OutputConfig) { output.FileCase = "kebabcase" }, "invalid file case"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := valid
+			test.change(&output)
+
+			err := validateOutputs([]OutputConfig{output})
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("got error %s", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v, want %s", err, test.wantErr)
+			}
+		})
+	}
+
+	err := validateOutputs([]OutputConfig{valid, valid})
+	if err == nil || !strings.Contains(err.Error(), "used multiple times") {
+		t.Errorf("got error %v, want duplicate name error", err)
+	}
+}
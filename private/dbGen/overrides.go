@@ -0,0 +1,211 @@
+package dbGen
+
+import (
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Template overrides customize template pack without copying it. TemplateOverrides folder mirrors layout of the pack,
+// file with the same path as template of the pack is parsed after it, so its {{define}} blocks replace blocks
+// of the pack and partials. When the file has content outside of blocks, it replaces the whole template
+
+const (
+	TemplateLayerPartial  = "partial"
+	TemplateLayerTemplate = "template"
+	TemplateLayerOverride = "override"
+)
+
+// parse name of override, it differs from template name, so errors point to the right file
+const overrideTemplatePrefix = "overrides/"
+
+type TemplateBlock struct {
+	Output   string
+	Name     string // name of block, body of template has name of template file
+	Layer    string // partial, template or override
+	Location string // file:line where block starts
+}
+
+// getTemplateOverride returns path of file overriding pack template, empty if there is none
+func getTemplateOverride(config *Config, packTemplate string) string {
+	if config.TemplateOverrides == "" {
+		return ""
+	}
+
+	overridePath := filepath.Join(config.TemplateOverrides, filepath.FromSlash(packTemplate))
+	if !common2.FileIsReadable(overridePath) {
+		return ""
+	}
+
+	common2.LogDebug("Template %s is overridden by %s", packTemplate, overridePath)
+	return overridePath
+}
+
+// validateTemplateOverrides checks that every file in overrides folder overrides template of pack,
+// otherwise typo in file name would silently do nothing
+func validateTemplateOverrides(config *Config, packTemplates []string) error {
+	if config.TemplateOverrides == "" {
+		return nil
+	}
+
+	if config.TemplatePack == "" {
+		return fmt.Errorf("TemplateOverrides can be used only with TemplatePack")
+	}
+
+	info, err := os.Stat(config.TemplateOverrides)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("TemplateOverrides %s is not folder", config.TemplateOverrides)
+	}
+
+	return filepath.WalkDir(config.TemplateOverrides, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		relPath, err := filepath.Rel(config.TemplateOverrides, filePath)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(packTemplates, filepath.ToSlash(relPath)) {
+			return fmt.Errorf("template override %s doesn't match any template of pack %s (%s)", filePath, config.TemplatePack, strings.Join(packTemplates, ", "))
+		}
+
+		return nil
+	})
+}
+
+// loadTemplateOverride loads override of output template as partial, nil if output has no override
+func loadTemplateOverride(output OutputConfig) (*templatePartial, error) {
+	if output.Override == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(output.Override)
+	if err != nil {
+		return nil, fmt.Errorf("reading template override: %s", err)
+	}
+
+	return &templatePartial{
+		name:    overrideTemplatePrefix + path.Base(filepath.ToSlash(output.Override)),
+		path:    output.Override,
+		content: string(content),
+	}, nil
+}
+
+// addTemplateOverride parses override into template set and returns template that should be executed
+func addTemplateOverride(tmpl *template.Template, override *templatePartial) (*template.Template, error) {
+	overrideTemplate, err := tmpl.New(override.name).Parse(override.content)
+	if err != nil {
+		return nil, err
+	}
+
+	// override with only blocks keeps body of the template
+	if overrideTemplate.Tree == nil || parse.IsEmptyTree(overrideTemplate.Tree.Root) {
+		return tmpl, nil
+	}
+
+	return overrideTemplate, nil
+}
+
+// GetTemplateBlocks returns blocks of output templates with layer they come from, all outputs when outputName is empty
+func GetTemplateBlocks(config *Config, outputName string) ([]TemplateBlock, error) {
+	partials, err := loadTemplatePartials(config)
+	if err != nil {
+		return nil, fmt.Errorf("loading template partials: %s", err)
+	}
+
+	blocks := make([]TemplateBlock, 0)
+	found := false
+
+	for _, output := range config.Outputs {
+		if outputName != "" && output.Name != outputName {
+			continue
+		}
+		found = true
+
+		outputBlocks, err := getOutputBlocks(output, partials)
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %s", output.Name, err)
+		}
+
+		blocks = append(blocks, outputBlocks...)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("output %s doesn't exist", outputName)
+	}
+
+	return blocks, nil
+}
+
+func getOutputBlocks(output OutputConfig, partials []templatePartial) ([]TemplateBlock, error) {
+	override, err := loadTemplateOverride(output)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := parseTemplate(output.Template, override, partials)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse name -> layer and file
+	mainName := path.Base(filepath.ToSlash(output.Template))
+	sources := map[string][2]string{mainName: {TemplateLayerTemplate, output.Template}}
+	for _, partial := range partials {
+		sources[partial.name] = [2]string{TemplateLayerPartial, partial.path}
+	}
+	if override != nil {
+		sources[override.name] = [2]string{TemplateLayerOverride, override.path}
+	}
+
+	blocks := make([]TemplateBlock, 0)
+	for _, definedTemplate := range tmpl.Templates() {
+		if definedTemplate.Tree == nil || parse.IsEmptyTree(definedTemplate.Tree.Root) {
+			continue
+		}
+
+		name := definedTemplate.Name()
+		if name == mainName || (override != nil && name == override.name) {
+			// body of the template is the executed one, the other is replaced
+			if definedTemplate != tmpl {
+				continue
+			}
+			name = mainName
+		}
+
+		tree := definedTemplate.Tree
+		source := sources[tree.ParseName]
+
+		location, _ := tree.ErrorContext(tree.Root)
+		location = strings.TrimPrefix(location, tree.ParseName+":")
+		line, _, _ := strings.Cut(location, ":")
+
+		blocks = append(blocks, TemplateBlock{
+			Output:   output.Name,
+			Name:     name,
+			Layer:    source[0],
+			Location: source[1] + ":" + line,
+		})
+	}
+
+	// body first, then blocks by name
+	sort.SliceStable(blocks, func(i, j int) bool {
+		if (blocks[i].Name == mainName) != (blocks[j].Name == mainName) {
+			return blocks[i].Name == mainName
+		}
+
+		return blocks[i].Name < blocks[j].Name
+	})
+
+	return blocks, nil
+}
@@ -0,0 +1,124 @@
+package dbGen
+
+import (
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Partials are template files parsed together with every template, so their {{define}} blocks can be used
+// with {{template "name" .}} or {{include "name" . | indent 4}}
+
+type templatePartial struct {
+	name    string // file path relative to partials folder, usable as template name
+	path    string
+	content string
+}
+
+// loadTemplatePartials loads files matched by TemplatePartials, which is either folder (all files recursively) or glob
+func loadTemplatePartials(config *Config) ([]templatePartial, error) {
+	if config.TemplatePartials == "" {
+		return nil, nil
+	}
+
+	files, baseFolder, err := getPartialFiles(config.TemplatePartials)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no template partials found in %s", config.TemplatePartials)
+	}
+
+	partials := make([]templatePartial, 0, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading template partial: %s", err)
+		}
+
+		name, err := filepath.Rel(baseFolder, file)
+		if err != nil {
+			return nil, err
+		}
+
+		common2.LogDebug("Loaded template partial %s", file)
+		partials = append(partials, templatePartial{name: filepath.ToSlash(name), path: file, content: string(content)})
+	}
+
+	return partials, nil
+}
+
+func getPartialFiles(pattern string) ([]string, string, error) {
+	info, err := os.Stat(pattern)
+	if err == nil && info.IsDir() {
+		files := make([]string, 0)
+		err = filepath.WalkDir(pattern, func(filePath string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				files = append(files, filePath)
+			}
+
+			return err
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("listing template partials: %s", err)
+		}
+
+		return files, pattern, nil
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid template partials pattern %s: %s", pattern, err)
+	}
+
+	files := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && !info.IsDir() {
+			files = append(files, match)
+		}
+	}
+
+	return files, getGlobBase(pattern), nil
+}
+
+// getGlobBase returns folder part of pattern before first component with wildcards
+func getGlobBase(pattern string) string {
+	base := filepath.Dir(pattern)
+	for strings.ContainsAny(base, "*?[") {
+		base = filepath.Dir(base)
+	}
+
+	return base
+}
+
+// addTemplatePartials parses partials into template set, they have to be added before the main template,
+// so blocks defined in the main template win
+func addTemplatePartials(tmpl *template.Template, partials []templatePartial) error {
+	for _, partial := range partials {
+		_, err := tmpl.New(partial.name).Parse(partial.content)
+		if err != nil {
+			return fmt.Errorf("parsing template partial %s: %s", partial.name, err)
+		}
+	}
+
+	return nil
+}
+
+// getIncludeFunction returns function rendering named template of the set to string, so it can be piped
+func getIncludeFunction(tmpl *template.Template) func(name string, data interface{}) (string, error) {
+	return func(name string, data interface{}) (string, error) {
+		var out strings.Builder
+
+		err := tmpl.ExecuteTemplate(&out, name, data)
+		if err != nil {
+			return "", err
+		}
+
+		return out.String(), nil
+	}
+}
@@ -0,0 +1,105 @@
+package dbGen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"github.com/keenmate/db-gen/private/version"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Plan shows what generate would do with output folder, files are rendered in memory and nothing is written
+
+const (
+	PlanCreate    = "create"
+	PlanModify    = "modify"
+	PlanUnchanged = "unchanged"
+	PlanDelete    = "delete"
+)
+
+type PlannedFile struct {
+	Path   string // relative to output folder
+	Action string
+	Output string // empty for deleted files
+	Diff   string // unified diff of the change, set only when requested
+}
+
+// PlanGenerate compares rendered files with output folder, previousInfo (can be nil) is used to find orphaned files
+func PlanGenerate(routines []Routine, config *Config, previousInfo *GenerationInformation, withDiff bool) ([]PlannedFile, error) {
+	files, err := renderOutputs(routines, version.GetBuildInfo(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	// compared content is what generate would write
+	err = preserveRegions(config, files)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]PlannedFile, 0, len(files))
+	generatedPaths := make([]string, 0, len(files))
+
+	for _, file := range files {
+		relPath := filepath.ToSlash(file.path)
+		generatedPaths = append(generatedPaths, relPath)
+
+		existing, err := os.ReadFile(filepath.Join(config.OutputFolder, file.path))
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: %s", file.path, err)
+		}
+
+		planned := PlannedFile{Path: relPath, Output: file.output}
+		switch {
+		case !exists:
+			planned.Action = PlanCreate
+		case bytes.Equal(existing, file.content):
+			planned.Action = PlanUnchanged
+		default:
+			planned.Action = PlanModify
+		}
+
+		if withDiff && planned.Action != PlanUnchanged {
+			fromName := path.Join("a", relPath)
+			if !exists {
+				fromName = "/dev/null"
+			}
+
+			planned.Diff = common2.UnifiedDiff(fromName, path.Join("b", relPath), string(existing), string(file.content))
+		}
+
+		plan = append(plan, planned)
+	}
+
+	deletedPaths, err := getDeletedFiles(config, previousInfo, generatedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, relPath := range deletedPaths {
+		content, err := os.ReadFile(filepath.Join(config.OutputFolder, filepath.FromSlash(relPath)))
+		if errors.Is(err, fs.ErrNotExist) {
+			// orphaned file was already deleted by hand
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %s", relPath, err)
+		}
+
+		warnAboutDeletedRegions(relPath, content)
+
+		planned := PlannedFile{Path: relPath, Action: PlanDelete}
+		if withDiff {
+			planned.Diff = common2.UnifiedDiff(path.Join("a", relPath), "/dev/null", string(content), "")
+		}
+
+		plan = append(plan, planned)
+	}
+
+	return plan, nil
+}
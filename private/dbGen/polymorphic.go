@@ -0,0 +1,146 @@
+package dbGen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Polymorphic pseudo-types are exposed as generic type parameters.
+// All types from one family share the same type parameter, because postgres resolves them to the same actual type.
+
+type polymorphicFamily struct {
+	typeParameter string
+	elementTypes  []string
+	arrayTypes    []string
+	rangeTypes    []string
+	multiranges   []string
+}
+
+var polymorphicFamilies = []polymorphicFamily{
+	{
+		typeParameter: "T",
+		elementTypes:  []string{"anyelement", "anynonarray", "anyenum"},
+		arrayTypes:    []string{"anyarray"},
+		rangeTypes:    []string{"anyrange"},
+		multiranges:   []string{"anymultirange"},
+	},
+	{
+		typeParameter: "U",
+		elementTypes:  []string{"anycompatible", "anycompatiblenonarray"},
+		arrayTypes:    []string{"anycompatiblearray"},
+		rangeTypes:    []string{"anycompatiblerange"},
+		multiranges:   []string{"anycompatiblemultirange"},
+	},
+}
+
+// placeholder in MappedType and MappingFunction that is replaced by type parameter (or range subtype)
+const typePlaceholder = "{{.}}"
+
+func getPolymorphicFamily(dbType string) (*polymorphicFamily, bool) {
+	for i, family := range polymorphicFamilies {
+		if family.contains(dbType) {
+			return &polymorphicFamilies[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func (family *polymorphicFamily) contains(dbType string) bool {
+	return slices.Contains(family.elementTypes, dbType) ||
+		slices.Contains(family.arrayTypes, dbType) ||
+		slices.Contains(family.rangeTypes, dbType) ||
+		slices.Contains(family.multiranges, dbType)
+}
+
+// pinParameter returns parameter with pseudo-type replaced by concrete type, when type parameter is pinned to concreteType
+func (family *polymorphicFamily) pinParameter(param DbParameter, concreteType string) (DbParameter, error) {
+	pinned := param
+	pinned.UDTName = concreteType
+
+	switch {
+	case slices.Contains(family.arrayTypes, param.UDTName):
+		pinned.UDTName = "_" + concreteType
+	case slices.Contains(family.rangeTypes, param.UDTName):
+		rangeType, ok := rangeTypesBySubtype[concreteType]
+		if !ok {
+			return pinned, fmt.Errorf("no range type known for pinned type %s", concreteType)
+		}
+		pinned.UDTName = rangeType
+		pinned.RangeSubtype = concreteType
+	case slices.Contains(family.multiranges, param.UDTName):
+		multirangeType, ok := multirangeTypesBySubtype[concreteType]
+		if !ok {
+			return pinned, fmt.Errorf("no multirange type known for pinned type %s", concreteType)
+		}
+		pinned.UDTName = multirangeType
+		pinned.RangeSubtype = concreteType
+		pinned.IsMultirange = true
+	}
+
+	return pinned, nil
+}
+
+// getPinnedType returns concrete type set in routine mapping for given type parameter
+func getPinnedType(typeParameter string, routineMapping *RoutineMapping) (string, bool) {
+	for name, concreteType := range routineMapping.TypeParameters {
+		// viper lowercases keys
+		if strings.EqualFold(name, typeParameter) {
+			return concreteType, true
+		}
+	}
+
+	return "", false
+}
+
+func validatePinnedTypeParameters(routineMapping *RoutineMapping) error {
+	for name := range routineMapping.TypeParameters {
+		known := slices.ContainsFunc(polymorphicFamilies, func(family polymorphicFamily) bool {
+			return strings.EqualFold(family.typeParameter, name)
+		})
+
+		if !known {
+			return fmt.Errorf("unknown type parameter %s, only %s can be pinned", name, strings.Join(getTypeParameterNames(), ", "))
+		}
+	}
+
+	return nil
+}
+
+func getTypeParameterNames() []string {
+	names := make([]string, len(polymorphicFamilies))
+	for i, family := range polymorphicFamilies {
+		names[i] = family.typeParameter
+	}
+
+	return names
+}
+
+// getRoutineTypeParameters returns type parameters used by any property in order of families
+func getRoutineTypeParameters(properties ...[]Property) []string {
+	typeParameters := make([]string, 0)
+
+	for _, typeParameter := range getTypeParameterNames() {
+		for _, propertyList := range properties {
+			used := slices.ContainsFunc(propertyList, func(property Property) bool {
+				return property.TypeParameter == typeParameter
+			})
+
+			if used {
+				typeParameters = append(typeParameters, typeParameter)
+				break
+			}
+		}
+	}
+
+	return typeParameters
+}
+
+func applyTypePlaceholder(typeMapping mapping, value string) *mapping {
+	return &mapping{
+		mappedFunction: strings.ReplaceAll(typeMapping.mappedFunction, typePlaceholder, value),
+		mappedType:     strings.ReplaceAll(typeMapping.mappedType, typePlaceholder, value),
+		fallbackTypes:  typeMapping.fallbackTypes,
+	}
+}
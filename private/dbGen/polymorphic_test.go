@@ -0,0 +1,156 @@
+package dbGen
+
+import (
+	"slices"
+	"testing"
+)
+
+func getPolymorphicTestMappings() *map[string]mapping {
+	return &map[string]mapping{
+		"int4":               {mappedType: "int", mappedFunction: "GetInt"},
+		"_int4":              {mappedType: "int[]", mappedFunction: "GetIntArray"},
+		"text":               {mappedType: "string", mappedFunction: "GetString"},
+		"anyarray":           {mappedType: "{{.}}[]", mappedFunction: "GetArray<{{.}}>"},
+		"anycompatiblearray": {mappedType: "{{.}}[]", mappedFunction: "GetArray<{{.}}>"},
+		"anyenum":            {mappedType: "string", mappedFunction: "GetString"},
+	}
+}
+
+func TestResolveTypeMappingPolymorphic(t *testing.T) {
+	tests := []struct {
+		name              string
+		dbType            string
+		typeParameters    map[string]string
+		wantType          string
+		wantFunction      string
+		wantTypeParameter string
+	}{
+		{"concrete type", "int4", nil, "int", "GetInt", ""},
+		{"element is type parameter", "anyelement", nil, "T", "", "T"},
+		{"compatible element", "anycompatible", nil, "U", "", "U"},
+		{"placeholder in array mapping", "anyarray", nil, "T[]", "GetArray<T>", "T"},
+		{"placeholder in compatible array mapping", "anycompatiblearray", nil, "U[]", "GetArray<U>", "U"},
+		{"mapping without placeholder", "anyenum", nil, "string", "GetString", ""},
+		{"pinned element", "anyelement", map[string]string{"t": "int4"}, "int", "GetInt", ""},
+		{"pinned array uses array type", "anyarray", map[string]string{"T": "int4"}, "int[]", "GetIntArray", ""},
+		{"other family is not pinned", "anycompatible", map[string]string{"t": "int4"}, "U", "", "U"},
+		{"pinned compatible element", "anycompatiblenonarray", map[string]string{"u": "text"}, "string", "GetString", ""},
+	}
+
+	for _, test := range tests {
+		param := DbParameter{Name: "value", UDTName: test.dbType}
+		routineMapping := &RoutineMapping{TypeParameters: test.typeParameters}
+
+		typeMapping, typeParameter, err := resolveTypeMapping(param, routineMapping, getPolymorphicTestMappings())
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if typeMapping.mappedType != test.wantType || typeMapping.mappedFunction != test.wantFunction || typeParameter != test.wantTypeParameter {
+			t.Errorf("%s: got %s %s %s, want %s %s %s", test.name, typeMapping.mappedType, typeMapping.mappedFunction, typeParameter,
+				test.wantType, test.wantFunction, test.wantTypeParameter)
+		}
+	}
+}
+
+func TestResolveTypeMappingMixedTypeParameters(t *testing.T) {
+	// fn(a anyelement, b anycompatiblearray) returns table (result anyelement, items anycompatiblearray)
+	parameters := []DbParameter{{Name: "a", UDTName: "anyelement"}, {Name: "b", UDTName: "anycompatiblearray"}}
+	columns := []DbParameter{{Name: "result", UDTName: "anyelement"}, {Name: "items", UDTName: "anycompatiblearray"}}
+
+	tests := []struct {
+		name               string
+		typeParameters     map[string]string
+		wantTypes          []string
+		wantTypeParameters []string
+	}{
+		{"generic", nil, []string{"T", "U[]", "T", "U[]"}, []string{"T", "U"}},
+		{"return type pinned with parameter", map[string]string{"T": "text"}, []string{"string", "U[]", "string", "U[]"}, []string{"U"}},
+		{"both pinned", map[string]string{"T": "text", "U": "int4"}, []string{"string", "int[]", "string", "int[]"}, []string{}},
+	}
+
+	for _, test := range tests {
+		routineMapping := &RoutineMapping{TypeParameters: test.typeParameters}
+
+		properties := make([][]Property, 2)
+		types := make([]string, 0)
+
+		for i, params := range [][]DbParameter{parameters, columns} {
+			for _, param := range params {
+				typeMapping, typeParameter, err := resolveTypeMapping(param, routineMapping, getPolymorphicTestMappings())
+				if err != nil {
+					t.Fatalf("%s: %s", test.name, err)
+				}
+
+				types = append(types, typeMapping.mappedType)
+				properties[i] = append(properties[i], Property{DbColumnName: param.Name, TypeParameter: typeParameter})
+			}
+		}
+
+		if !slices.Equal(types, test.wantTypes) {
+			t.Errorf("%s: got types %v, want %v", test.name, types, test.wantTypes)
+		}
+
+		// parameters and columns share type parameters of routine
+		if got := getRoutineTypeParameters(properties...); !slices.Equal(got, test.wantTypeParameters) {
+			t.Errorf("%s: got type parameters %v, want %v", test.name, got, test.wantTypeParameters)
+		}
+	}
+}
+
+func TestGetRoutineTypeParametersOrder(t *testing.T) {
+	parameters := []Property{{TypeParameter: "U"}}
+	columns := []Property{{TypeParameter: "T"}, {TypeParameter: "U"}}
+
+	if got := getRoutineTypeParameters(parameters, columns); !slices.Equal(got, []string{"T", "U"}) {
+		t.Errorf("got %v, want [T U]", got)
+	}
+}
+
+func TestPinParameter(t *testing.T) {
+	tests := []struct {
+		dbType           string
+		concreteType     string
+		wantType         string
+		wantRangeSubtype string
+		wantMultirange   bool
+	}{
+		{"anyelement", "int4", "int4", "", false},
+		{"anynonarray", "text", "text", "", false},
+		{"anyarray", "int4", "_int4", "", false},
+		{"anycompatiblearray", "text", "_text", "", false},
+		{"anyrange", "date", "daterange", "date", false},
+		{"anycompatiblerange", "numeric", "numrange", "numeric", false},
+		{"anymultirange", "timestamptz", "tstzmultirange", "timestamptz", true},
+	}
+
+	for _, test := range tests {
+		family, _ := getPolymorphicFamily(test.dbType)
+
+		pinned, err := family.pinParameter(DbParameter{Name: "value", UDTName: test.dbType}, test.concreteType)
+		if err != nil {
+			t.Errorf("%s pinned to %s: %s", test.dbType, test.concreteType, err)
+			continue
+		}
+
+		if pinned.UDTName != test.wantType || pinned.RangeSubtype != test.wantRangeSubtype || pinned.IsMultirange != test.wantMultirange || pinned.Name != "value" {
+			t.Errorf("%s pinned to %s: got %+v", test.dbType, test.concreteType, pinned)
+		}
+	}
+
+	family, _ := getPolymorphicFamily("anyrange")
+	if _, err := family.pinParameter(DbParameter{UDTName: "anyrange"}, "text"); err == nil {
+		t.Error("range of text didn't fail")
+	}
+}
+
+func TestValidatePinnedTypeParameters(t *testing.T) {
+	if err := validatePinnedTypeParameters(&RoutineMapping{TypeParameters: map[string]string{"t": "int4", "U": "text"}}); err != nil {
+		t.Error(err)
+	}
+
+	if err := validatePinnedTypeParameters(&RoutineMapping{TypeParameters: map[string]string{"V": "int4"}}); err == nil {
+		t.Error("unknown type parameter didn't fail")
+	}
+}
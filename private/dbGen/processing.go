@@ -19,6 +19,12 @@ func Process(routines []DbRoutine, config *Config) ([]Routine, error) {
 	typeMappings := getTypeMappings(config)
 	helpers.LogDebug("Got %d type mappings", len(typeMappings))
 
+	// without fallback mapping would stop at first unmapped type, placeholder fallback lets it collect all of them,
+	// it never gets to generated code, because generation fails when any fallback is used
+	if _, hasFallback := typeMappings[fallbackMappingKey]; config.StrictMappings && !hasFallback {
+		typeMappings[fallbackMappingKey] = mapping{}
+	}
+
 	// Map routines
 	functions, err := mapRoutines(&filteredRoutines, &typeMappings, config)
 
@@ -27,6 +33,13 @@ func Process(routines []DbRoutine, config *Config) ([]Routine, error) {
 		return nil, fmt.Errorf("mapping functions: %s", err)
 	}
 
+	if config.StrictMappings {
+		report := GetFallbackMappingReport(functions)
+		if !report.IsEmpty() {
+			return nil, fmt.Errorf("strict mappings enabled and these types have no mapping:\n%s", report.Details())
+		}
+	}
+
 	return functions, nil
 
 }
@@ -0,0 +1,85 @@
+package dbGen
+
+import (
+	"strings"
+	"testing"
+)
+
+func getStrictMappingsTestRoutines() []DbRoutine {
+	return []DbRoutine{
+		{
+			RoutineSchema: "public",
+			RoutineName:   "add_payment",
+			DataType:      "void",
+			UdtTypeName:   "void",
+			FuncType:      "function",
+			InParameters: []DbParameter{
+				{OrdinalPosition: 1, Name: "user_id", Mode: InMode, UDTName: "int4"},
+				{OrdinalPosition: 2, Name: "amount", Mode: InMode, UDTName: "money"},
+				{OrdinalPosition: 3, Name: "ip", Mode: InMode, UDTName: "inet"},
+			},
+		},
+		{
+			RoutineSchema: "public",
+			RoutineName:   "get_payments",
+			DataType:      "record",
+			UdtTypeName:   "record",
+			FuncType:      "function",
+			OutParameters: []DbParameter{
+				{OrdinalPosition: 1, Name: "amount", Mode: OutMode, UDTName: "money"},
+				{OrdinalPosition: 2, Name: "location", Mode: OutMode, UDTName: "point"},
+			},
+		},
+	}
+}
+
+func TestProcessStrictMappingsReportsAllTypes(t *testing.T) {
+	want := " - inet:\n" +
+		"\t public.add_payment parameter ip\n" +
+		" - money:\n" +
+		"\t public.add_payment parameter amount\n" +
+		"\t public.get_payments column amount\n" +
+		" - point:\n" +
+		"\t public.get_payments column location\n"
+
+	mappings := []Mapping{{DatabaseTypes: []string{"int4"}, MappedType: "int"}}
+	withFallback := append(mappings, Mapping{DatabaseTypes: []string{"*"}, MappedType: "object"})
+
+	// with fallback, all usages are listed, without it mapping doesn't stop at first unmapped type
+	for _, typeMappings := range [][]Mapping{withFallback, mappings} {
+		config := &Config{
+			StrictMappings: true,
+			Mappings:       typeMappings,
+			Generate:       []SchemaConfig{{Schema: "public", AllFunctions: true}},
+		}
+
+		_, err := Process(getStrictMappingsTestRoutines(), config)
+		if err == nil || !strings.HasSuffix(err.Error(), want) {
+			t.Errorf("%d mappings: got error %v, want report\n%s", len(typeMappings), err, want)
+		}
+	}
+}
+
+func TestProcessWithoutStrictMappings(t *testing.T) {
+	config := &Config{
+		Mappings: []Mapping{{DatabaseTypes: []string{"int4"}, MappedType: "int"}},
+		Generate: []SchemaConfig{{Schema: "public", AllFunctions: true}},
+	}
+
+	// without fallback, generation fails on first unmapped type
+	_, err := Process(getStrictMappingsTestRoutines(), config)
+	if err == nil || !strings.Contains(err.Error(), "'money' not found and fallback processing * is not set") {
+		t.Errorf("got error %v, want missing mapping of money", err)
+	}
+
+	config.Mappings = append(config.Mappings, Mapping{DatabaseTypes: []string{"*"}, MappedType: "object"})
+
+	routines, err := Process(getStrictMappingsTestRoutines(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report := GetFallbackMappingReport(routines); report.Summary() != "inet (1x), money (2x), point (1x)" {
+		t.Errorf("got fallback report %s", report.Summary())
+	}
+}
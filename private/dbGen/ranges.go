@@ -0,0 +1,97 @@
+package dbGen
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Range and multirange types are mapped using mapping of their subtype.
+// Mapping for exact range type or for anyrange/anymultirange is used as wrapper, {{.}} is replaced by mapped subtype.
+
+const (
+	rangeWrapperKey      = "anyrange"
+	multirangeWrapperKey = "anymultirange"
+)
+
+// built-in range types by their subtype, used when type parameter is pinned to concrete type
+var rangeTypesBySubtype = map[string]string{
+	"int4":        "int4range",
+	"int8":        "int8range",
+	"numeric":     "numrange",
+	"timestamp":   "tsrange",
+	"timestamptz": "tstzrange",
+	"date":        "daterange",
+}
+
+var multirangeTypesBySubtype = map[string]string{
+	"int4":        "int4multirange",
+	"int8":        "int8multirange",
+	"numeric":     "nummultirange",
+	"timestamp":   "tsmultirange",
+	"timestamptz": "tstzmultirange",
+	"date":        "datemultirange",
+}
+
+// rangeInfoColumns returns select columns range_subtype and is_multirange for type identified by schema and name columns.
+// rngmultitypid only exists since PG14, reading it through jsonb keeps the query working on older versions
+func rangeInfoColumns(schemaColumn string, nameColumn string) string {
+	return fmt.Sprintf(`coalesce((select st.typname::text
+			          from pg_type t
+			          join pg_namespace n on n.oid = t.typnamespace
+			          join pg_range rng on rng.rngtypid = t.oid or (to_jsonb(rng) ->> 'rngmultitypid')::oid = t.oid
+			          join pg_type st on st.oid = rng.rngsubtype
+			          where n.nspname = %[1]s and t.typname = %[2]s), '') as range_subtype,
+			   coalesce((select t.typtype = 'm'
+			          from pg_type t
+			          join pg_namespace n on n.oid = t.typnamespace
+			          where n.nspname = %[1]s and t.typname = %[2]s), false) as is_multirange`, schemaColumn, nameColumn)
+}
+
+// getRangeKind returns if parameter is range and if it is multirange, polymorphic ranges included
+func getRangeKind(param DbParameter) (bool, bool) {
+	if param.RangeSubtype != "" {
+		return true, param.IsMultirange
+	}
+
+	family, isPolymorphic := getPolymorphicFamily(param.UDTName)
+	if !isPolymorphic {
+		return false, false
+	}
+
+	if slices.Contains(family.multiranges, param.UDTName) {
+		return true, true
+	}
+
+	return slices.Contains(family.rangeTypes, param.UDTName), false
+}
+
+// getConcreteTypeMapping gets mapping for non-polymorphic type, ranges are derived from their subtype
+func getConcreteTypeMapping(param DbParameter, globalTypesMappings *map[string]mapping) (*mapping, error) {
+	if param.RangeSubtype == "" {
+		return getTypeMapping(param.UDTName, globalTypesMappings)
+	}
+
+	wrapperMapping, exists := (*globalTypesMappings)[param.UDTName]
+	if !exists {
+		wrapperKey := rangeWrapperKey
+		if param.IsMultirange {
+			wrapperKey = multirangeWrapperKey
+		}
+
+		wrapperMapping, exists = (*globalTypesMappings)[wrapperKey]
+	}
+
+	if !exists {
+		return getTypeMapping(param.UDTName, globalTypesMappings)
+	}
+
+	subtypeMapping, err := getTypeMapping(param.RangeSubtype, globalTypesMappings)
+	if err != nil {
+		return nil, fmt.Errorf("mapping subtype of range %s: %s", param.UDTName, err)
+	}
+
+	rangeMapping := applyTypePlaceholder(wrapperMapping, subtypeMapping.mappedType)
+	rangeMapping.fallbackTypes = subtypeMapping.fallbackTypes
+
+	return rangeMapping, nil
+}
@@ -0,0 +1,123 @@
+package dbGen
+
+import (
+	"slices"
+	"testing"
+)
+
+func getRangeTestMappings() map[string]mapping {
+	return map[string]mapping{
+		"int4":          {mappedType: "int", mappedFunction: "GetInt"},
+		"date":          {mappedType: "DateOnly", mappedFunction: "GetDate"},
+		"timestamptz":   {mappedType: "DateTime", mappedFunction: "GetDateTime"},
+		"anyrange":      {mappedType: "NpgsqlRange<{{.}}>", mappedFunction: "GetRange<{{.}}>"},
+		"anymultirange": {mappedType: "NpgsqlRange<{{.}}>[]", mappedFunction: "GetMultirange<{{.}}>"},
+		"daterange":     {mappedType: "DateRange<{{.}}>", mappedFunction: "GetDateRange"},
+		"*":             {mappedType: "object", mappedFunction: "GetValue"},
+	}
+}
+
+func TestGetConcreteTypeMappingRanges(t *testing.T) {
+	tests := []struct {
+		name          string
+		param         DbParameter
+		wantType      string
+		wantFunction  string
+		wantFallbacks []string
+	}{
+		{"not range", DbParameter{UDTName: "int4"}, "int", "GetInt", nil},
+		{"range wrapper", DbParameter{UDTName: "int4range", RangeSubtype: "int4"}, "NpgsqlRange<int>", "GetRange<int>", nil},
+		{"exact range mapping wins", DbParameter{UDTName: "daterange", RangeSubtype: "date"}, "DateRange<DateOnly>", "GetDateRange", nil},
+		{"multirange wrapper", DbParameter{UDTName: "tstzmultirange", RangeSubtype: "timestamptz", IsMultirange: true}, "NpgsqlRange<DateTime>[]", "GetMultirange<DateTime>", nil},
+		{"subtype uses fallback", DbParameter{UDTName: "numrange", RangeSubtype: "numeric"}, "NpgsqlRange<object>", "GetRange<object>", []string{"numeric"}},
+	}
+
+	for _, test := range tests {
+		mappings := getRangeTestMappings()
+
+		typeMapping, err := getConcreteTypeMapping(test.param, &mappings)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if typeMapping.mappedType != test.wantType || typeMapping.mappedFunction != test.wantFunction || !slices.Equal(typeMapping.fallbackTypes, test.wantFallbacks) {
+			t.Errorf("%s: got %s %s %v, want %s %s %v", test.name, typeMapping.mappedType, typeMapping.mappedFunction, typeMapping.fallbackTypes,
+				test.wantType, test.wantFunction, test.wantFallbacks)
+		}
+	}
+}
+
+func TestGetConcreteTypeMappingRangeWithoutWrapper(t *testing.T) {
+	mappings := map[string]mapping{
+		"int4":      {mappedType: "int"},
+		"int4range": {mappedType: "IntRange"},
+	}
+
+	// range is mapped as any other type
+	typeMapping, err := getConcreteTypeMapping(DbParameter{UDTName: "int4range", RangeSubtype: "int4"}, &mappings)
+	if err != nil || typeMapping.mappedType != "IntRange" {
+		t.Errorf("got %v %v, want IntRange", typeMapping, err)
+	}
+
+	// subtype without mapping and fallback
+	mappings["anyrange"] = mapping{mappedType: "Range<{{.}}>"}
+	_, err = getConcreteTypeMapping(DbParameter{UDTName: "tsrange", RangeSubtype: "timestamp"}, &mappings)
+	if err == nil {
+		t.Error("range of unmapped subtype didn't fail")
+	}
+}
+
+func TestResolveTypeMappingPolymorphicRanges(t *testing.T) {
+	tests := []struct {
+		name              string
+		dbType            string
+		typeParameters    map[string]string
+		wantType          string
+		wantTypeParameter string
+	}{
+		{"generic range", "anyrange", nil, "NpgsqlRange<T>", "T"},
+		{"generic multirange", "anymultirange", nil, "NpgsqlRange<T>[]", "T"},
+		{"pinned range", "anyrange", map[string]string{"T": "int4"}, "NpgsqlRange<int>", ""},
+		{"pinned range with exact mapping", "anyrange", map[string]string{"T": "date"}, "DateRange<DateOnly>", ""},
+		{"pinned multirange wraps subtype", "anymultirange", map[string]string{"T": "date"}, "NpgsqlRange<DateOnly>[]", ""},
+		{"pinned compatible multirange", "anycompatiblemultirange", map[string]string{"U": "timestamptz"}, "NpgsqlRange<DateTime>[]", ""},
+	}
+
+	for _, test := range tests {
+		mappings := getRangeTestMappings()
+		routineMapping := &RoutineMapping{TypeParameters: test.typeParameters}
+
+		typeMapping, typeParameter, err := resolveTypeMapping(DbParameter{UDTName: test.dbType}, routineMapping, &mappings)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if typeMapping.mappedType != test.wantType || typeParameter != test.wantTypeParameter {
+			t.Errorf("%s: got %s %s, want %s %s", test.name, typeMapping.mappedType, typeParameter, test.wantType, test.wantTypeParameter)
+		}
+	}
+}
+
+func TestGetRangeKind(t *testing.T) {
+	tests := []struct {
+		param          DbParameter
+		wantRange      bool
+		wantMultirange bool
+	}{
+		{DbParameter{UDTName: "int4"}, false, false},
+		{DbParameter{UDTName: "int4range", RangeSubtype: "int4"}, true, false},
+		{DbParameter{UDTName: "int4multirange", RangeSubtype: "int4", IsMultirange: true}, true, true},
+		{DbParameter{UDTName: "anyrange"}, true, false},
+		{DbParameter{UDTName: "anycompatiblemultirange"}, true, true},
+		{DbParameter{UDTName: "anyelement"}, false, false},
+	}
+
+	for _, test := range tests {
+		isRange, isMultirange := getRangeKind(test.param)
+		if isRange != test.wantRange || isMultirange != test.wantMultirange {
+			t.Errorf("%s: got range %t, multirange %t", test.param.UDTName, isRange, isMultirange)
+		}
+	}
+}
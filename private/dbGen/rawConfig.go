@@ -0,0 +1,160 @@
+package dbGen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// viper lowercases all keys, values where key case matters (json schemas...) are read from raw configuration.
+// Raw configuration is merged the same way as viper merges configuration files
+
+var rawConfig = make(map[string]interface{})
+
+// keys of values that are restored from raw configuration, keys of their objects are lowercased by viper
+// (string value of JsonSchema is path to file)
+var caseSensitiveKeys = []string{"JsonSchema", "Variables", "Extra"}
+
+func mergeRawConfig(content []byte, configType string) error {
+	fileConfig := make(map[string]interface{})
+
+	var err error
+	switch configType {
+	case "json":
+		err = json.Unmarshal(content, &fileConfig)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(content, &fileConfig)
+	case "toml":
+		err = toml.Unmarshal(content, &fileConfig)
+	default:
+		return checkCaseSensitiveKeys(content, configType)
+	}
+
+	if err != nil {
+		return fmt.Errorf("parsing raw configuration: %s", err)
+	}
+
+	mergeRawMaps(rawConfig, fileConfig)
+	return nil
+}
+
+// checkCaseSensitiveKeys fails when configuration that can't be read raw has values where case of keys matters,
+// they would be silently lowercased
+func checkCaseSensitiveKeys(content []byte, configType string) error {
+	fileViper := viper.New()
+	fileViper.SetConfigType(configType)
+
+	err := fileViper.ReadConfig(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("reading configuration: %s", err)
+	}
+
+	key, found := findCaseSensitiveKey(fileViper.AllSettings())
+	if found {
+		return fmt.Errorf("%s can't be set in %s configuration, because case of its keys is lost, use json, yaml or toml configuration", key, configType)
+	}
+
+	return nil
+}
+
+func findCaseSensitiveKey(value interface{}) (string, bool) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typed {
+			_, isString := item.(string)
+			for _, sensitiveKey := range caseSensitiveKeys {
+				if !isString && strings.EqualFold(key, sensitiveKey) {
+					return sensitiveKey, true
+				}
+			}
+
+			if key, found := findCaseSensitiveKey(item); found {
+				return key, true
+			}
+		}
+	case []interface{}:
+		for _, item := range typed {
+			if key, found := findCaseSensitiveKey(item); found {
+				return key, true
+			}
+		}
+	// hcl blocks
+	case []map[string]interface{}:
+		for _, item := range typed {
+			if key, found := findCaseSensitiveKey(item); found {
+				return key, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func mergeRawMaps(target map[string]interface{}, source map[string]interface{}) {
+	for sourceKey, sourceValue := range source {
+		targetKey, exists := findRawKey(target, sourceKey)
+		if !exists {
+			target[sourceKey] = sourceValue
+			continue
+		}
+
+		targetMap, targetIsMap := target[targetKey].(map[string]interface{})
+		sourceMap, sourceIsMap := sourceValue.(map[string]interface{})
+		if targetIsMap && sourceIsMap {
+			mergeRawMaps(targetMap, sourceMap)
+			continue
+		}
+
+		delete(target, targetKey)
+		target[sourceKey] = sourceValue
+	}
+}
+
+func findRawKey(m map[string]interface{}, key string) (string, bool) {
+	if _, exists := m[key]; exists {
+		return key, true
+	}
+
+	for existingKey := range m {
+		if strings.EqualFold(existingKey, key) {
+			return existingKey, true
+		}
+	}
+
+	return "", false
+}
+
+// getRawConfigValue finds value by path of keys (string) and array indexes (int), keys are case-insensitive
+func getRawConfigValue(path ...interface{}) (interface{}, bool) {
+	var current interface{} = rawConfig
+
+	for _, segment := range path {
+		switch key := segment.(type) {
+		case string:
+			currentMap, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			rawKey, exists := findRawKey(currentMap, key)
+			if !exists {
+				return nil, false
+			}
+			current = currentMap[rawKey]
+		case int:
+			currentArray, ok := current.([]interface{})
+			if !ok || key >= len(currentArray) {
+				return nil, false
+			}
+			current = currentArray[key]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
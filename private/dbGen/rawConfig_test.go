@@ -0,0 +1,91 @@
+package dbGen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeRawConfigKeepsCase(t *testing.T) {
+	tests := []struct {
+		configType string
+		content    string
+	}{
+		{"json", `{"Variables": {"NameSpace": "My.App"}, "Generate": [{"Schema": "public"}]}`},
+		{"yaml", "Variables:\n  NameSpace: My.App\nGenerate:\n  - Schema: public\n"},
+		{"yml", "Variables:\n  NameSpace: My.App\nGenerate:\n  - Schema: public\n"},
+		{"toml", "[Variables]\nNameSpace = 'My.App'\n\n[[Generate]]\nSchema = 'public'\n"},
+	}
+
+	defer func() { rawConfig = make(map[string]interface{}) }()
+
+	for _, test := range tests {
+		rawConfig = make(map[string]interface{})
+
+		err := mergeRawConfig([]byte(test.content), test.configType)
+		if err != nil {
+			t.Errorf("%s: %s", test.configType, err)
+			continue
+		}
+
+		variables, _ := getRawConfigValue("variables")
+		if variablesMap, _ := variables.(map[string]interface{}); variablesMap["NameSpace"] != "My.App" {
+			t.Errorf("%s: variables %v don't keep case of keys", test.configType, variables)
+		}
+
+		if schema, _ := getRawConfigValue("Generate", 0, "schema"); schema != "public" {
+			t.Errorf("%s: schema of first Generate is %v", test.configType, schema)
+		}
+	}
+}
+
+func TestMergeRawConfigMergesFiles(t *testing.T) {
+	defer func() { rawConfig = make(map[string]interface{}) }()
+	rawConfig = make(map[string]interface{})
+
+	err := mergeRawConfig([]byte(`{"Variables": {"NameSpace": "My.App", "Author": "me"}}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// local configuration overrides value with key in different case
+	err = mergeRawConfig([]byte("variables:\n  namespace: Local.App\n"), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	variables, _ := getRawConfigValue("Variables")
+	want := map[string]interface{}{"namespace": "Local.App", "Author": "me"}
+	if got, _ := variables.(map[string]interface{}); len(got) != len(want) || got["namespace"] != want["namespace"] || got["Author"] != want["Author"] {
+		t.Errorf("merged variables are %v, want %v", variables, want)
+	}
+}
+
+func TestMergeRawConfigRejectsLowercasedValues(t *testing.T) {
+	defer func() { rawConfig = make(map[string]interface{}) }()
+
+	tests := []struct {
+		name    string
+		content string
+		wantKey string
+	}{
+		{"variables", "Variables {\n  NameSpace = \"My.App\"\n}\n", "Variables"},
+		{"inline schema", "Generate {\n  Functions {\n    fn {\n      Model {\n        data {\n          JsonSchema {\n            type = \"object\"\n          }\n        }\n      }\n    }\n  }\n}\n", "JsonSchema"},
+		{"schema file", "Generate {\n  Functions {\n    fn {\n      Model {\n        data {\n          JsonSchema = \"./schema.json\"\n        }\n      }\n    }\n  }\n}\n", ""},
+		{"other values", "OutputFolder = \"./output\"\n", ""},
+	}
+
+	for _, test := range tests {
+		err := mergeRawConfig([]byte(test.content), "hcl")
+
+		if test.wantKey == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %s", test.name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.HasPrefix(err.Error(), test.wantKey+" can't be set in hcl configuration") {
+			t.Errorf("%s: got error %v, want error about %s", test.name, err, test.wantKey)
+		}
+	}
+}
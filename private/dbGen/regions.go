@@ -0,0 +1,196 @@
+package dbGen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Protected regions keep hand-written lines in generated files. Template emits region markers in comment
+// of the target language, e.g.
+//
+//	// db-gen:begin custom imports
+//	// db-gen:end
+//
+// and lines between markers are copied from the previous version of the file, so they survive regeneration.
+// Lines generated inside region are used only when the previous file doesn't have the region
+
+const (
+	regionBeginMarker = "db-gen:begin"
+	regionEndMarker   = "db-gen:end"
+)
+
+// comment terminators after region name, e.g. <!-- db-gen:begin head -->
+var regionNameTerminators = []string{"-->", "*/", "*)", "%>", "#}"}
+
+type fileRegion struct {
+	name    string
+	content []byte // lines between markers, including line endings
+}
+
+// preserveRegions replaces content of regions in rendered files with content of regions in output folder
+func preserveRegions(config *Config, files []generatedFile) error {
+	for i, file := range files {
+		existing, err := os.ReadFile(filepath.Join(config.OutputFolder, file.path))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %s", file.path, err)
+		}
+
+		content, err := mergeRegions(file.path, file.content, existing)
+		if err != nil {
+			return err
+		}
+
+		files[i].content = content
+	}
+
+	return nil
+}
+
+// mergeRegions returns generated content with regions of existing file, regions missing in generated content are reported
+func mergeRegions(filePath string, generated []byte, existing []byte) ([]byte, error) {
+	existingRegions, err := parseRegions(existing)
+	if err != nil {
+		return nil, fmt.Errorf("%s has invalid protected region, fix it by hand so its content is not lost: %s", filePath, err)
+	}
+
+	// regions are checked even if existing file has none, so template error shows up on first run
+	generatedRegions, err := parseRegions(generated)
+	if err != nil {
+		return nil, fmt.Errorf("template generated invalid protected region in %s: %s", filePath, err)
+	}
+
+	for _, region := range existingRegions {
+		_, kept := findRegion(generatedRegions, region.name)
+		if !kept && len(bytes.TrimSpace(region.content)) > 0 {
+			common2.LogWarn("Protected region %q disappeared from %s, its content is not kept:\n%s", region.name, filePath, bytes.TrimRight(region.content, "\r\n"))
+		}
+	}
+
+	if len(existingRegions) == 0 {
+		return generated, nil
+	}
+
+	merged := make([]byte, 0, len(generated))
+	inRegion := false
+
+	for _, line := range bytes.SplitAfter(generated, []byte("\n")) {
+		if inRegion && !isRegionEnd(line) {
+			// generated lines are replaced by existing region
+			continue
+		}
+		inRegion = false
+
+		merged = append(merged, line...)
+
+		name, isBegin := getRegionName(line)
+		if !isBegin {
+			continue
+		}
+
+		if region, found := findRegion(existingRegions, name); found {
+			merged = append(merged, region.content...)
+			inRegion = true
+		}
+	}
+
+	return merged, nil
+}
+
+// warnAboutDeletedRegions prints content of protected regions of file that is going to be deleted,
+// e.g. when routine was renamed or Path template changed, so hand-written lines can be moved by hand
+func warnAboutDeletedRegions(filePath string, content []byte) {
+	regions, err := parseRegions(content)
+	if err != nil {
+		common2.LogWarn("Deleted file %s has invalid protected region, check its content in version control: %s", filePath, err)
+		return
+	}
+
+	for _, region := range regions {
+		if len(bytes.TrimSpace(region.content)) > 0 {
+			common2.LogWarn("Protected region %q is deleted with %s, its content is not kept:\n%s", region.name, filePath, bytes.TrimRight(region.content, "\r\n"))
+		}
+	}
+}
+
+// parseRegions returns regions of file in order, regions can't be nested and their names must be unique
+func parseRegions(content []byte) ([]fileRegion, error) {
+	regions := make([]fileRegion, 0)
+	var current *fileRegion
+
+	for number, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if name, isBegin := getRegionName(line); isBegin {
+			if name == "" {
+				return nil, fmt.Errorf("line %d: region without name", number+1)
+			}
+
+			if current != nil {
+				return nil, fmt.Errorf("line %d: region %q starts inside region %q", number+1, name, current.name)
+			}
+
+			if _, found := findRegion(regions, name); found {
+				return nil, fmt.Errorf("line %d: region %q is defined more than once", number+1, name)
+			}
+
+			current = &fileRegion{name: name, content: make([]byte, 0)}
+			continue
+		}
+
+		if isRegionEnd(line) {
+			if current == nil {
+				return nil, fmt.Errorf("line %d: %s without %s", number+1, regionEndMarker, regionBeginMarker)
+			}
+
+			regions = append(regions, *current)
+			current = nil
+			continue
+		}
+
+		if current != nil {
+			current.content = append(current.content, line...)
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("region %q is not closed with %s", current.name, regionEndMarker)
+	}
+
+	return regions, nil
+}
+
+// getRegionName returns name of region started on line, name is the rest of the line after marker
+func getRegionName(line []byte) (string, bool) {
+	_, name, found := strings.Cut(string(line), regionBeginMarker)
+	if !found {
+		return "", false
+	}
+
+	name = strings.TrimSpace(name)
+	for _, terminator := range regionNameTerminators {
+		name = strings.TrimSpace(strings.TrimSuffix(name, terminator))
+	}
+
+	return name, true
+}
+
+func isRegionEnd(line []byte) bool {
+	return bytes.Contains(line, []byte(regionEndMarker))
+}
+
+func findRegion(regions []fileRegion, name string) (fileRegion, bool) {
+	for _, region := range regions {
+		if region.name == name {
+			return region, true
+		}
+	}
+
+	return fileRegion{}, false
+}
@@ -0,0 +1,147 @@
+package dbGen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRegions(t *testing.T) {
+	content := "using System;\n" +
+		"// db-gen:begin usings\n" +
+		"using Custom;\n" +
+		"// db-gen:end\n" +
+		"<!-- db-gen:begin head -->\n" +
+		"<!-- db-gen:end -->\n" +
+		"/* db-gen:begin attributes */\r\n" +
+		"[Custom]\r\n" +
+		"/* db-gen:end */\r\n"
+
+	regions, err := parseRegions([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []fileRegion{
+		{name: "usings", content: []byte("using Custom;\n")},
+		{name: "head", content: []byte("")},
+		{name: "attributes", content: []byte("[Custom]\r\n")},
+	}
+
+	if len(regions) != len(want) {
+		t.Fatalf("got %d regions, want %d", len(regions), len(want))
+	}
+
+	for i, region := range regions {
+		if region.name != want[i].name || string(region.content) != string(want[i].content) {
+			t.Errorf("region %d = %q %q, want %q %q", i, region.name, region.content, want[i].name, want[i].content)
+		}
+	}
+}
+
+func TestParseRegionsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "nested",
+			content: "// db-gen:begin outer\n// db-gen:begin inner\n// db-gen:end\n// db-gen:end\n",
+			want:    `line 2: region "inner" starts inside region "outer"`,
+		},
+		{
+			name:    "duplicate",
+			content: "// db-gen:begin usings\n// db-gen:end\n// db-gen:begin usings\n// db-gen:end\n",
+			want:    `line 3: region "usings" is defined more than once`,
+		},
+		{
+			name:    "missing end",
+			content: "// db-gen:begin usings\nusing Custom;\n",
+			want:    `region "usings" is not closed with db-gen:end`,
+		},
+		{
+			name:    "missing begin",
+			content: "using Custom;\n// db-gen:end\n",
+			want:    "line 2: db-gen:end without db-gen:begin",
+		},
+		{
+			name:    "missing name",
+			content: "<!-- db-gen:begin -->\n<!-- db-gen:end -->\n",
+			want:    "line 1: region without name",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := parseRegions([]byte(test.content))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("%s: got error %v, want %s", test.name, err, test.want)
+		}
+	}
+}
+
+func TestMergeRegions(t *testing.T) {
+	tests := []struct {
+		name      string
+		generated string
+		existing  string
+		want      string
+	}{
+		{
+			name:      "existing content is kept",
+			generated: "class User\n// db-gen:begin members\n// generated default\n// db-gen:end\nend\n",
+			existing:  "class OldUser\n// db-gen:begin members\nint Custom;\n// db-gen:end\nend\n",
+			want:      "class User\n// db-gen:begin members\nint Custom;\n// db-gen:end\nend\n",
+		},
+		{
+			name:      "new region uses generated content",
+			generated: "// db-gen:begin usings\nusing System;\n// db-gen:end\n// db-gen:begin members\n// generated default\n// db-gen:end\n",
+			existing:  "// db-gen:begin usings\nusing Custom;\n// db-gen:end\n",
+			want:      "// db-gen:begin usings\nusing Custom;\n// db-gen:end\n// db-gen:begin members\n// generated default\n// db-gen:end\n",
+		},
+		{
+			name:      "region removed from template",
+			generated: "class User\nend\n",
+			existing:  "class User\n// db-gen:begin members\nint Custom;\n// db-gen:end\nend\n",
+			want:      "class User\nend\n",
+		},
+		{
+			name:      "line endings of both files are kept",
+			generated: "class User\r\n// db-gen:begin members\r\n// db-gen:end\r\nend\r\n",
+			existing:  "// db-gen:begin members\nint Custom;\n// db-gen:end\n",
+			want:      "class User\r\n// db-gen:begin members\r\nint Custom;\n// db-gen:end\r\nend\r\n",
+		},
+		{
+			name:      "file without regions",
+			generated: "class User\nend\n",
+			existing:  "class OldUser\nend\n",
+			want:      "class User\nend\n",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := mergeRegions("user.cs", []byte(test.generated), []byte(test.existing))
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("%s:\ngot:\n%q\nwant:\n%q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestMergeRegionsInvalid(t *testing.T) {
+	valid := "// db-gen:begin members\n// db-gen:end\n"
+	invalid := "// db-gen:begin members\n"
+
+	_, err := mergeRegions("user.cs", []byte(valid), []byte(invalid))
+	if err == nil || !strings.Contains(err.Error(), "user.cs has invalid protected region") {
+		t.Errorf("invalid existing file: got error %v", err)
+	}
+
+	_, err = mergeRegions("user.cs", []byte(invalid), []byte(valid))
+	if err == nil || !strings.Contains(err.Error(), "template generated invalid protected region in user.cs") {
+		t.Errorf("invalid generated file: got error %v", err)
+	}
+}
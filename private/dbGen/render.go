@@ -0,0 +1,208 @@
+package dbGen
+
+import (
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"github.com/keenmate/db-gen/private/version"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Single template can be rendered for one item without writing any file, so templates can be developed quickly.
+// Filter of output is ignored, the item is rendered even when it would be filtered out
+
+// location in text/template errors, e.g. "template: model.gotmpl:7:23: executing..." (column is missing in parse errors)
+var templateErrorLocationRegex = regexp.MustCompile(`template: ([^:\s]+):(\d+)(?::(\d+))?:`)
+
+// RenderTemplate renders output (by name) or template file with scope, item selects routine, schema, enum... by name
+func RenderTemplate(config *Config, routines []Routine, templateOrOutput string, scope string, itemName string) (string, error) {
+	output, err := getRenderOutput(config, templateOrOutput, scope, itemName)
+	if err != nil {
+		return "", err
+	}
+
+	partials, err := loadTemplatePartials(config)
+	if err != nil {
+		return "", fmt.Errorf("loading template partials: %s", err)
+	}
+
+	override, err := loadTemplateOverride(output)
+	if err != nil {
+		return "", err
+	}
+
+	// errors can point to override, it is searched the same way as partials
+	errorSources := partials
+	if override != nil {
+		errorSources = append(slices.Clone(partials), *override)
+	}
+
+	outputTemplate, err := parseTemplate(output.Template, override, partials)
+	if err != nil {
+		return "", fmt.Errorf("loading template: %s%s", err, getTemplateErrorContext(err, output.Template, errorSources))
+	}
+
+	templateData, err := getItemTemplateData(output, routines, itemName, config)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	err = outputTemplate.Execute(&out, templateData)
+	if err != nil {
+		return "", fmt.Errorf("rendering template: %s%s", err, getTemplateErrorContext(err, output.Template, errorSources))
+	}
+
+	return out.String(), nil
+}
+
+// getRenderOutput finds output by name, otherwise creates output for template file,
+// scope of template file defaults to routine when item is set and to global otherwise
+func getRenderOutput(config *Config, templateOrOutput string, scope string, itemName string) (OutputConfig, error) {
+	for _, output := range config.Outputs {
+		if output.Name == templateOrOutput {
+			if scope != "" && scope != output.Scope {
+				return OutputConfig{}, fmt.Errorf("output %s has scope %s, scope cannot be changed", output.Name, output.Scope)
+			}
+
+			return output, nil
+		}
+	}
+
+	if scope == "" {
+		scope = ScopeGlobal
+		if itemName != "" {
+			scope = ScopeRoutine
+		}
+	}
+
+	if !slices.Contains(validScopes, scope) {
+		return OutputConfig{}, fmt.Errorf("invalid scope '%s', valid scopes are %s", scope, strings.Join(validScopes, ", "))
+	}
+
+	if !isBuiltinPath(templateOrOutput) && !common2.PathExists(templateOrOutput) {
+		return OutputConfig{}, fmt.Errorf("%s is neither output name nor template file", templateOrOutput)
+	}
+
+	return OutputConfig{Name: templateOrOutput, Template: templateOrOutput, Scope: scope}, nil
+}
+
+func getItemTemplateData(output OutputConfig, routines []Routine, itemName string, config *Config) (interface{}, error) {
+	items, err := getOutputItems(output, routines, version.GetBuildInfo(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := findOutputItem(items, output.Scope, itemName)
+	if err != nil {
+		return nil, err
+	}
+
+	return item.templateData, nil
+}
+
+// findOutputItem finds item by any of its names, global scope has only one item
+func findOutputItem(items []outputItem, scope string, itemName string) (*outputItem, error) {
+	if scope == ScopeGlobal {
+		if len(items) == 0 {
+			return nil, fmt.Errorf("nothing to render")
+		}
+
+		return &items[0], nil
+	}
+
+	available := make([]string, 0, len(items))
+	matches := make([]*outputItem, 0)
+	for i, item := range items {
+		available = append(available, item.names[0])
+
+		if itemName != "" && slices.Contains(item.names, itemName) {
+			matches = append(matches, &items[i])
+		}
+	}
+
+	switch {
+	case len(items) == 0:
+		return nil, fmt.Errorf("there is no %s to render", scope)
+	case itemName == "":
+		return nil, fmt.Errorf("%s scope requires item name, available: %s", scope, strings.Join(available, ", "))
+	case len(matches) == 0:
+		return nil, fmt.Errorf("%s '%s' not found, available: %s", scope, itemName, strings.Join(available, ", "))
+	case len(matches) > 1:
+		// overloaded routines have the same database name
+		names := make([]string, len(matches))
+		for i, match := range matches {
+			names[i] = match.names[len(match.names)-1]
+		}
+
+		return nil, fmt.Errorf("%s '%s' is ambiguous, use one of: %s", scope, itemName, strings.Join(names, ", "))
+	default:
+		return matches[0], nil
+	}
+}
+
+// getTemplateErrorContext returns line of template file where error occurred, with column marked when it is known.
+// Errors of included templates contain multiple locations, the last one is where the error occurred
+func getTemplateErrorContext(err error, templatePath string, partials []templatePartial) string {
+	matches := templateErrorLocationRegex.FindAllStringSubmatch(err.Error(), -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	match := matches[len(matches)-1]
+
+	content, err := getTemplateContent(match[1], templatePath, partials)
+	if err != nil {
+		return ""
+	}
+
+	lineNumber, _ := strconv.Atoi(match[2])
+	lines := strings.Split(content, "\n")
+	if lineNumber < 1 || lineNumber > len(lines) {
+		return ""
+	}
+
+	line := strings.TrimRight(lines[lineNumber-1], "\r")
+	prefix := fmt.Sprintf("%5d | ", lineNumber)
+	context := "\n" + prefix + line
+
+	if match[3] != "" {
+		column, _ := strconv.Atoi(match[3])
+		if column <= len(line) {
+			// keep tabs, so the marker is aligned with the line
+			padding := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, line[:column])
+
+			context += "\n" + strings.Repeat(" ", len(prefix)-2) + "| " + padding + "^"
+		}
+	}
+
+	return context
+}
+
+// getTemplateContent returns content of main template or partial by template name used in errors
+func getTemplateContent(name string, templatePath string, partials []templatePartial) (string, error) {
+	for _, partial := range partials {
+		if partial.name == name {
+			return partial.content, nil
+		}
+	}
+
+	if name != path.Base(filepath.ToSlash(templatePath)) {
+		return "", fmt.Errorf("unknown template %s", name)
+	}
+
+	content, err := readTemplateFile(templatePath)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
@@ -14,29 +14,38 @@ type DbRoutine struct {
 	RoutineSchema         string `db:"routine_schema"`
 	RoutineNameWithParams string
 	HasOverload           bool
-	RoutineName           string `db:"routine_name"`
-	SpecificName          string `db:"specific_name"`
-	DataType              string `db:"data_type"`
-	UdtTypeScheme         string `db:"type_udt_schema"`
-	UdtTypeName           string `db:"type_udt_name"`
-	ParamCount            int    `db:"param_count"`
-	FuncType              string `db:"func_type"`
+	RoutineName           string     `db:"routine_name"`
+	SpecificName          string     `db:"specific_name"`
+	DataType              string     `db:"data_type"`
+	UdtTypeScheme         string     `db:"type_udt_schema"`
+	UdtTypeName           string     `db:"type_udt_name"`
+	ParamCount            int        `db:"param_count"`
+	FuncType              string     `db:"func_type"`
+	RangeSubtype          string     `db:"range_subtype"`
+	IsMultirange          bool       `db:"is_multirange"`
+	EnumValues            StringList `db:"enum_values"` // set when routine returns enum
 	InParameters          []DbParameter
 	OutParameters         []DbParameter
 }
 
 type DbParameter struct {
-	OrdinalPosition int    `db:"ordinal_position"`
-	Name            string `db:"parameter_name"`
-	Mode            string `db:"parameter_mode"` // IN/OUT
-	UDTName         string `db:"udt_name"`       // User defined type
-	IsNullable      bool   `db:"is_nullable"`
-	IsOptional      bool   `db:"is_optional"`
+	OrdinalPosition int        `db:"ordinal_position"`
+	Name            string     `db:"parameter_name"`
+	Mode            string     `db:"parameter_mode"` // IN/OUT/INOUT
+	UDTName         string     `db:"udt_name"`       // User defined type
+	UDTSchema       string     `db:"udt_schema"`
+	IsNullable      bool       `db:"is_nullable"`
+	IsOptional      bool       `db:"is_optional"`
+	RangeSubtype    string     `db:"range_subtype"`
+	IsMultirange    bool       `db:"is_multirange"`
+	Comment         string     `db:"comment"` // only columns of tables and types have comments
+	EnumValues      StringList `db:"enum_values"`
 }
 
 const (
 	OutMode   = "OUT"
 	InMode    = "IN"
+	InOutMode = "INOUT"
 	Procedure = "procedure"
 )
 
@@ -129,7 +138,9 @@ func getFunctionsInSchema(conn *database.DbConn, schema string) ([]DbRoutine, er
 	        coalesce(r.type_udt_schema::text,'') as type_udt_schema,
 	        coalesce(r.type_udt_name::text,'') as type_udt_name,
 	        coalesce(param_count,0) as param_count,
-	        case when r.data_type is null  then 'procedure' else 'function' end as func_type
+	        case when r.data_type is null  then 'procedure' else 'function' end as func_type,
+	        ` + rangeInfoColumns("r.type_udt_schema", "r.type_udt_name") + `,
+	        ` + enumValuesColumn("r.type_udt_schema", "r.type_udt_name") + `
 	        
 	      from information_schema.routines r
 	      left join (select specific_schema, specific_name, count(*) as param_count from
@@ -153,19 +164,29 @@ func addParamsToRoutine(conn *database.DbConn, routine *DbRoutine) error {
 			   parameter_name::text,
 			   parameter_mode::text,
 			   udt_name::text,
+			   udt_schema::text,
 			   false as is_nullable,
-			   parameter_default is not null as is_optional
+			   parameter_default is not null as is_optional,
+			   ` + rangeInfoColumns("udt_schema", "udt_name") + `,
+			   '' as comment,
+			   ` + enumValuesColumn("udt_schema", "udt_name") + `
 			
 		from information_schema.parameters
 		where specific_schema = $1
 		  and specific_name = $2		
 		union
-		select c.ordinal_position::int, c.column_name::text, 'OUT', c.udt_name::text, c.is_nullable = 'YES',true
+		select c.ordinal_position::int, c.column_name::text, 'OUT', c.udt_name::text, c.udt_schema::text, c.is_nullable = 'YES',true,
+			   ` + rangeInfoColumns("c.udt_schema", "c.udt_name") + `,
+			   coalesce(col_description(format('%I.%I', c.table_schema, c.table_name)::regclass, c.ordinal_position::int), ''),
+			   ` + enumValuesColumn("c.udt_schema", "c.udt_name") + `
 		from information_schema.columns c
 		where c.table_name = $3
 		  and c.table_schema = coalesce($4, 'public')
 		union
-		select a.ordinal_position::int, a.attribute_name::text, 'OUT', a.attribute_udt_name::text, is_nullable = 'YES',true
+		select a.ordinal_position::int, a.attribute_name::text, 'OUT', a.attribute_udt_name::text, a.attribute_udt_schema::text, is_nullable = 'YES',true,
+			   ` + rangeInfoColumns("a.attribute_udt_schema", "a.attribute_udt_name") + `,
+			   coalesce(col_description(format('%I.%I', a.udt_schema, a.udt_name)::regclass, a.ordinal_position::int), ''),
+			   ` + enumValuesColumn("a.attribute_udt_schema", "a.attribute_udt_name") + `
 		from information_schema.attributes a
 		where a.udt_name = $3
 		  and a.udt_schema = coalesce($4, 'public')
@@ -188,6 +209,11 @@ func addParamsToRoutine(conn *database.DbConn, routine *DbRoutine) error {
 		case OutMode:
 			routine.OutParameters = append(routine.OutParameters, param)
 			break
+		case InOutMode:
+			// value is passed and returned
+			routine.InParameters = append(routine.InParameters, param)
+			routine.OutParameters = append(routine.OutParameters, param)
+			break
 		}
 	}
 	return nil
@@ -199,6 +225,11 @@ func createRoutineNameWithParams(routine *DbRoutine) string {
 
 	params := make([]string, 0)
 	for _, param := range routine.InParameters {
+		// INOUT parameters were not loaded before, they are left out so Functions keys of existing configurations still match
+		if param.Mode == InOutMode {
+			continue
+		}
+
 		params = append(params, param.UDTName)
 	}
 
@@ -0,0 +1,43 @@
+package dbGen
+
+import "testing"
+
+func TestCreateRoutineNameWithParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters []DbParameter
+		want       string
+	}{
+		{"no parameters", nil, "fn()"},
+		{
+			name: "in parameters",
+			parameters: []DbParameter{
+				{Name: "name", Mode: InMode, UDTName: "text"},
+				{Name: "age", Mode: InMode, UDTName: "int4"},
+			},
+			want: "fn(text,int4)",
+		},
+		{
+			// key is the same as before INOUT parameters were loaded
+			name:       "only inout parameter",
+			parameters: []DbParameter{{Name: "counter", Mode: InOutMode, UDTName: "int4"}},
+			want:       "fn()",
+		},
+		{
+			name: "in and inout parameters",
+			parameters: []DbParameter{
+				{Name: "name", Mode: InMode, UDTName: "text"},
+				{Name: "counter", Mode: InOutMode, UDTName: "int4"},
+				{Name: "age", Mode: InMode, UDTName: "int4"},
+			},
+			want: "fn(text,int4)",
+		},
+	}
+
+	for _, test := range tests {
+		routine := DbRoutine{RoutineName: "fn", InParameters: test.parameters}
+		if got := createRoutineNameWithParams(&routine); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package dbGen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Routine call statements ready to be used in templates, parameters are $n placeholders
+type RoutineSql struct {
+	Columns         string // selected (not skipped) columns, e.g. id, "user"
+	Positional      string // select id, name from public.get_users($1, $2)
+	PositionalTyped string // select id, name from public.get_users($1::int4, $2::text)
+	Named           string // select id, name from public.get_users(p_id => $1, p_name => $2)
+	NamedTyped      string // select id, name from public.get_users(p_id => $1::int4, p_name => $2::text)
+	Required        string // named notation without optional parameters, placeholders are numbered by required parameters
+	RequiredTyped   string
+}
+
+// identifiers matching this and not being keywords don't have to be quoted
+var simpleIdentifierRegex = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// postgres keywords that quote_ident quotes (reserved, type/function name and column name keywords)
+var quotedKeywords = []string{
+	"all", "analyse", "analyze", "and", "any", "array", "as", "asc", "asymmetric", "authorization", "between", "bigint",
+	"binary", "bit", "boolean", "both", "case", "cast", "char", "character", "check", "coalesce", "collate", "collation",
+	"column", "concurrently", "constraint", "create", "cross", "current_catalog", "current_date", "current_role",
+	"current_schema", "current_time", "current_timestamp", "current_user", "dec", "decimal", "default", "deferrable",
+	"desc", "distinct", "do", "else", "end", "except", "exists", "extract", "false", "fetch", "float", "for", "foreign",
+	"freeze", "from", "full", "grant", "greatest", "group", "grouping", "having", "ilike", "in", "initially", "inner",
+	"inout", "int", "integer", "intersect", "interval", "into", "is", "isnull", "join", "json", "json_array",
+	"json_arrayagg", "json_exists", "json_object", "json_objectagg", "json_query", "json_scalar", "json_serialize",
+	"json_table", "json_value", "lateral", "leading", "least", "left", "like", "limit", "localtime", "localtimestamp",
+	"merge_action", "national", "natural", "nchar", "none", "normalize", "not", "notnull", "null", "nullif", "numeric",
+	"offset", "on", "only", "or", "order", "out", "outer", "overlaps", "overlay", "placing", "position", "precision",
+	"primary", "real", "references", "returning", "right", "row", "select", "session_user", "setof", "similar",
+	"smallint", "some", "substring", "symmetric", "system_user", "table", "tablesample", "then", "time", "timestamp",
+	"to", "trailing", "treat", "trim", "true", "union", "unique", "user", "using", "values", "varchar", "variadic",
+	"verbose", "when", "where", "window", "with", "xmlattributes", "xmlconcat", "xmlelement", "xmlexists", "xmlforest",
+	"xmlnamespaces", "xmlparse", "xmlpi", "xmlroot", "xmlserialize", "xmltable",
+}
+
+// schemas whose types don't have to be qualified in casts
+const catalogSchema = "pg_catalog"
+
+// quoteIdent quotes identifier only when necessary, same as postgres quote_ident
+func quoteIdent(identifier string) string {
+	if simpleIdentifierRegex.MatchString(identifier) && !slices.Contains(quotedKeywords, identifier) {
+		return identifier
+	}
+
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+// quoteLiteral quotes string constant, same as postgres quote_literal
+func quoteLiteral(value string) string {
+	quoted := "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	if strings.Contains(value, `\`) {
+		return "E" + strings.ReplaceAll(quoted, `\`, `\\`)
+	}
+
+	return quoted
+}
+
+func quoteQualifiedName(schema string, name string) string {
+	return quoteIdent(schema) + "." + quoteIdent(name)
+}
+
+// getRoutineSql builds call statements, dbParameters and mapped parameters have to be in the same order
+func getRoutineSql(routine DbRoutine, dbParameters []DbParameter, parameters []Property, columns []Property) RoutineSql {
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = quoteIdent(column.DbColumnName)
+	}
+
+	// procedure OUT parameters are not passed, but they have to be in call (as null) since postgres 14
+	outParameters := make([]DbParameter, 0)
+	if routine.FuncType == Procedure {
+		for _, parameter := range routine.OutParameters {
+			if parameter.Mode == OutMode {
+				outParameters = append(outParameters, parameter)
+			}
+		}
+	}
+
+	// named notation is possible only if all parameters have names
+	hasNoName := func(parameter DbParameter) bool {
+		return parameter.Name == ""
+	}
+	namedNotation := !slices.ContainsFunc(dbParameters, hasNoName) && !slices.ContainsFunc(outParameters, hasNoName)
+
+	argument := func(name string, value string) string {
+		if !namedNotation {
+			return value
+		}
+
+		return quoteIdent(name) + " => " + value
+	}
+
+	positional := make([]string, len(parameters))
+	positionalTyped := make([]string, len(parameters))
+	named := make([]string, len(parameters))
+	namedTyped := make([]string, len(parameters))
+	ordinals := make([]int, len(parameters))
+	required := make([]string, 0)
+	requiredTyped := make([]string, 0)
+	requiredOrdinals := make([]int, 0)
+
+	for i, parameter := range parameters {
+		name := dbParameters[i].Name
+		cast := getParameterCast(dbParameters[i])
+		placeholder := fmt.Sprintf("$%d", i+1)
+
+		ordinals[i] = dbParameters[i].OrdinalPosition
+		positional[i] = placeholder
+		positionalTyped[i] = placeholder + cast
+		named[i] = argument(name, placeholder)
+		namedTyped[i] = argument(name, placeholder+cast)
+
+		// without named notation, parameters can't be skipped
+		if !parameter.Optional || !namedNotation {
+			requiredPlaceholder := fmt.Sprintf("$%d", len(required)+1)
+
+			required = append(required, argument(name, requiredPlaceholder))
+			requiredTyped = append(requiredTyped, argument(name, requiredPlaceholder+cast))
+			requiredOrdinals = append(requiredOrdinals, dbParameters[i].OrdinalPosition)
+		}
+	}
+
+	// adds null for every OUT parameter of procedure at its position
+	withOutArguments := func(arguments []string, argumentOrdinals []int, named bool) []string {
+		if len(outParameters) == 0 {
+			return arguments
+		}
+
+		outArgument := func(parameter DbParameter) string {
+			if named {
+				return argument(parameter.Name, "null")
+			}
+
+			return "null"
+		}
+
+		merged := make([]string, 0, len(arguments)+len(outParameters))
+		next := 0
+		for i, value := range arguments {
+			for next < len(outParameters) && outParameters[next].OrdinalPosition < argumentOrdinals[i] {
+				merged = append(merged, outArgument(outParameters[next]))
+				next++
+			}
+
+			merged = append(merged, value)
+		}
+
+		for _, parameter := range outParameters[next:] {
+			merged = append(merged, outArgument(parameter))
+		}
+
+		return merged
+	}
+
+	routineName := quoteQualifiedName(routine.RoutineSchema, routine.RoutineName)
+	callStatement := func(arguments []string) string {
+		call := routineName + "(" + strings.Join(arguments, ", ") + ")"
+
+		switch {
+		case routine.FuncType == Procedure:
+			return "call " + call
+		case len(quotedColumns) > 0:
+			return "select " + strings.Join(quotedColumns, ", ") + " from " + call
+		default:
+			return "select " + call
+		}
+	}
+
+	return RoutineSql{
+		Columns:         strings.Join(quotedColumns, ", "),
+		Positional:      callStatement(withOutArguments(positional, ordinals, false)),
+		PositionalTyped: callStatement(withOutArguments(positionalTyped, ordinals, false)),
+		Named:           callStatement(withOutArguments(named, ordinals, true)),
+		NamedTyped:      callStatement(withOutArguments(namedTyped, ordinals, true)),
+		Required:        callStatement(withOutArguments(required, requiredOrdinals, true)),
+		RequiredTyped:   callStatement(withOutArguments(requiredTyped, requiredOrdinals, true)),
+	}
+}
+
+// getParameterCast returns cast to database type of parameter, polymorphic parameters are not cast
+func getParameterCast(dbParameter DbParameter) string {
+	if _, isPolymorphic := getPolymorphicFamily(dbParameter.UDTName); isPolymorphic || dbParameter.UDTName == "" {
+		return ""
+	}
+
+	if dbParameter.UDTSchema == "" || dbParameter.UDTSchema == catalogSchema {
+		return "::" + quoteIdent(dbParameter.UDTName)
+	}
+
+	return "::" + quoteQualifiedName(dbParameter.UDTSchema, dbParameter.UDTName)
+}
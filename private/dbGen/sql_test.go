@@ -0,0 +1,111 @@
+package dbGen
+
+import "testing"
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		identifier string
+		want       string
+	}{
+		{"users", "users"},
+		{"user_id", "user_id"},
+		{"_private", "_private"},
+		{"price$", "price$"},
+		{"user", `"user"`},
+		{"select", `"select"`},
+		{"order", `"order"`},
+		{"Users", `"Users"`},
+		{"USER_ID", `"USER_ID"`},
+		{"1st", `"1st"`},
+		{"first name", `"first name"`},
+		{"", `""`},
+		{`my"table`, `"my""table"`},
+		{`"`, `""""`},
+		{"it's", `"it's"`},
+		{`back\slash`, `"back\slash"`},
+		{"žluťoučký", `"žluťoučký"`},
+	}
+
+	for _, test := range tests {
+		if got := quoteIdent(test.identifier); got != test.want {
+			t.Errorf("quoteIdent(%q) = %s, want %s", test.identifier, got, test.want)
+		}
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", "''"},
+		{"text", "'text'"},
+		{"it's", "'it''s'"},
+		{"''", "''''''"},
+		{`say "hi"`, `'say "hi"'`},
+		{`back\slash`, `E'back\\slash'`},
+		{`\'; drop table users; --`, `E'\\''; drop table users; --'`},
+		{"line\nbreak", "'line\nbreak'"},
+	}
+
+	for _, test := range tests {
+		if got := quoteLiteral(test.value); got != test.want {
+			t.Errorf("quoteLiteral(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestGetRoutineSqlProcedureOutParameters(t *testing.T) {
+	routine := DbRoutine{
+		RoutineSchema: "public",
+		RoutineName:   "transfer",
+		FuncType:      Procedure,
+	}
+
+	in := DbParameter{OrdinalPosition: 1, Name: "amount", Mode: InMode, UDTName: "int4"}
+	inOut := DbParameter{OrdinalPosition: 3, Name: "balance", Mode: InOutMode, UDTName: "int4"}
+	optional := DbParameter{OrdinalPosition: 4, Name: "note", Mode: InMode, UDTName: "text", IsOptional: true}
+	out := DbParameter{OrdinalPosition: 2, Name: "status", Mode: OutMode, UDTName: "text"}
+	lastOut := DbParameter{OrdinalPosition: 5, Name: "user", Mode: OutMode, UDTName: "text"}
+
+	routine.InParameters = []DbParameter{in, inOut, optional}
+	routine.OutParameters = []DbParameter{out, inOut, lastOut}
+	parameters := []Property{{}, {}, {Optional: true}}
+
+	sql := getRoutineSql(routine, routine.InParameters, parameters, nil)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Positional", sql.Positional, "call public.transfer($1, null, $2, $3, null)"},
+		{"PositionalTyped", sql.PositionalTyped, "call public.transfer($1::int4, null, $2::int4, $3::text, null)"},
+		{"Named", sql.Named, `call public.transfer(amount => $1, status => null, balance => $2, note => $3, "user" => null)`},
+		{"Required", sql.Required, `call public.transfer(amount => $1, status => null, balance => $2, "user" => null)`},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s = %s, want %s", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestGetRoutineSqlFunctionOutParameters(t *testing.T) {
+	routine := DbRoutine{
+		RoutineSchema: "public",
+		RoutineName:   "get_balance",
+		FuncType:      "function",
+		InParameters:  []DbParameter{{OrdinalPosition: 1, Name: "id", Mode: InMode, UDTName: "int4"}},
+		OutParameters: []DbParameter{{OrdinalPosition: 2, Name: "balance", Mode: OutMode, UDTName: "int4"}},
+	}
+
+	sql := getRoutineSql(routine, routine.InParameters, []Property{{}}, []Property{{DbColumnName: "balance"}})
+
+	// OUT parameters of functions are returned columns, not arguments
+	want := "select balance from public.get_balance($1)"
+	if sql.Positional != want {
+		t.Errorf("Positional = %s, want %s", sql.Positional, want)
+	}
+}
@@ -0,0 +1,118 @@
+package dbGen
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Targets generate the same routines with different templates, mappings, naming... in one run.
+// Every target is root configuration with values set in the target replaced, lists and objects are not merged
+
+// routines are loaded only once, so these keys cannot be set per target
+var sharedConfigKeys = []string{"ConnectionString", "RoutinesFile", "UseRoutinesFile", "Targets"}
+
+const targetNameKey = "Name"
+
+func getTargetConfigs(root *Config, targetNames []string) ([]*Config, error) {
+	if len(root.Targets) == 0 {
+		if len(targetNames) > 0 {
+			return nil, fmt.Errorf("targets %s selected, but configuration has no targets", strings.Join(targetNames, ", "))
+		}
+
+		return []*Config{root}, nil
+	}
+
+	targets := make([]*Config, 0, len(root.Targets))
+	availableNames := make([]string, 0, len(root.Targets))
+	// output folder -> target name
+	outputFolders := make(map[string]string, len(root.Targets))
+
+	for i, rawTarget := range root.Targets {
+		target, err := decodeTarget(root, rawTarget, i)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Contains(availableNames, target.TargetName) {
+			return nil, fmt.Errorf("target name %s is used multiple times", target.TargetName)
+		}
+		availableNames = append(availableNames, target.TargetName)
+
+		// targets would overwrite generation information of each other and delete each other's files as orphaned,
+		// all targets are checked, not only selected ones, as they can be generated one by one
+		outputFolder := filepath.Clean(joinIfRelative(root.PathBase, target.OutputFolder))
+		if otherTarget, used := outputFolders[outputFolder]; used {
+			return nil, fmt.Errorf("targets %s and %s have the same OutputFolder %s, every target needs its own folder", otherTarget, target.TargetName, outputFolder)
+		}
+		outputFolders[outputFolder] = target.TargetName
+
+		if len(targetNames) == 0 || slices.Contains(targetNames, target.TargetName) {
+			targets = append(targets, target)
+		}
+	}
+
+	for _, targetName := range targetNames {
+		if !slices.Contains(availableNames, targetName) {
+			return nil, fmt.Errorf("target %s not found, available targets are %s", targetName, strings.Join(availableNames, ", "))
+		}
+	}
+
+	return targets, nil
+}
+
+func decodeTarget(root *Config, rawTarget map[string]interface{}, index int) (*Config, error) {
+	nameKey, hasName := findRawKey(rawTarget, targetNameKey)
+	name, _ := rawTarget[nameKey].(string)
+	if !hasName || name == "" {
+		return nil, fmt.Errorf("target %d has no name", index)
+	}
+
+	for _, sharedKey := range sharedConfigKeys {
+		if _, exists := findRawKey(rawTarget, sharedKey); exists {
+			return nil, fmt.Errorf("target %s: %s cannot be set per target", name, sharedKey)
+		}
+	}
+
+	target := *root
+	target.Targets = nil
+	target.TargetName = name
+
+	err := decodeOverWithHook(rawTarget, &target)
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %s", name, err)
+	}
+
+	restoreJsonSchemas(&target, "Targets", index)
+	restoreVariables(&target, "Targets", index)
+	restoreExtras(&target, "Targets", index)
+
+	return &target, nil
+}
+
+// GetIntrospectionConfig returns configuration used to load routines of all targets at once
+func GetIntrospectionConfig(configs []*Config) *Config {
+	introspectionConfig := *configs[0]
+	introspectionConfig.Generate = make([]SchemaConfig, 0)
+
+	for _, config := range configs {
+		introspectionConfig.Generate = append(introspectionConfig.Generate, config.Generate...)
+	}
+
+	return &introspectionConfig
+}
+
+// GetTargetRoutines returns routines from schemas used by target
+func GetTargetRoutines(routines []DbRoutine, config *Config) []DbRoutine {
+	schemas := getSchemas(config)
+	targetRoutines := make([]DbRoutine, 0, len(routines))
+
+	for _, routine := range routines {
+		if slices.Contains(schemas, routine.RoutineSchema) {
+			targetRoutines = append(targetRoutines, routine)
+		}
+	}
+
+	return targetRoutines
+}
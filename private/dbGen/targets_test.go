@@ -0,0 +1,201 @@
+package dbGen
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func getTargetsTestRoot() *Config {
+	return &Config{
+		PathBase:          "/project",
+		ConnectionString:  "postgresql://localhost/db",
+		OutputFolder:      "./output",
+		GeneratedFileCase: "camelcase",
+		Variables:         map[string]interface{}{"namespace": "Root", "author": "me"},
+		Mappings:          []Mapping{{DatabaseTypes: []string{"int4"}, MappedType: "int"}, {DatabaseTypes: []string{"text"}, MappedType: "string"}},
+		Generate:          []SchemaConfig{{Schema: "public", AllFunctions: true}},
+	}
+}
+
+func TestGetTargetConfigsWithoutTargets(t *testing.T) {
+	root := getTargetsTestRoot()
+
+	configs, err := getTargetConfigs(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(configs) != 1 || configs[0] != root {
+		t.Errorf("got %v, want root configuration", configs)
+	}
+
+	_, err = getTargetConfigs(root, []string{"csharp"})
+	if err == nil {
+		t.Error("selected target in configuration without targets didn't fail")
+	}
+}
+
+func TestGetTargetConfigsReplacesValues(t *testing.T) {
+	root := getTargetsTestRoot()
+	root.Targets = []map[string]interface{}{
+		{"name": "csharp", "outputfolder": "./cs"},
+		{
+			"name":              "go",
+			"outputfolder":      "./go",
+			"generatedfilecase": "snakecase",
+			"variables":         map[string]interface{}{"package": "db"},
+			"mappings":          []interface{}{map[string]interface{}{"databasetypes": []interface{}{"int4"}, "mappedtype": "int32"}},
+			"generate":          []interface{}{map[string]interface{}{"schema": "go", "allfunctions": true}},
+		},
+	}
+
+	configs, err := getTargetConfigs(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(configs) != 2 || configs[0].TargetName != "csharp" || configs[1].TargetName != "go" {
+		t.Fatalf("got %d targets, want csharp and go", len(configs))
+	}
+
+	csharp, golang := configs[0], configs[1]
+
+	// values not set in target are inherited from root, shared values are always the root ones
+	if csharp.OutputFolder != "./cs" || csharp.GeneratedFileCase != "camelcase" || len(csharp.Mappings) != 2 || !maps.Equal(csharp.Variables, root.Variables) {
+		t.Errorf("csharp target doesn't inherit root values: %+v", csharp)
+	}
+
+	for _, config := range configs {
+		if config.ConnectionString != root.ConnectionString || config.Targets != nil {
+			t.Errorf("target %s: shared values are not kept", config.TargetName)
+		}
+	}
+
+	// lists and objects set in target replace root values, they are not merged
+	if golang.GeneratedFileCase != "snakecase" {
+		t.Errorf("go target has GeneratedFileCase %s", golang.GeneratedFileCase)
+	}
+
+	if !maps.Equal(golang.Variables, map[string]interface{}{"package": "db"}) {
+		t.Errorf("go target has variables %v, root variables should be replaced", golang.Variables)
+	}
+
+	if len(golang.Mappings) != 1 || golang.Mappings[0].MappedType != "int32" {
+		t.Errorf("go target has mappings %v, root mappings should be replaced", golang.Mappings)
+	}
+
+	if len(golang.Generate) != 1 || golang.Generate[0].Schema != "go" {
+		t.Errorf("go target generates %v, root Generate should be replaced", golang.Generate)
+	}
+
+	// root is not changed by targets
+	if len(root.Mappings) != 2 || root.Variables["namespace"] != "Root" || root.Generate[0].Schema != "public" {
+		t.Errorf("root configuration was changed: %+v", root)
+	}
+}
+
+func TestGetTargetConfigsSelectsTargets(t *testing.T) {
+	root := getTargetsTestRoot()
+	root.Targets = []map[string]interface{}{
+		{"name": "csharp", "outputfolder": "./cs"},
+		{"name": "go", "outputfolder": "./go"},
+		{"name": "ts", "outputfolder": "./ts"},
+	}
+
+	configs, err := getTargetConfigs(root, []string{"ts", "csharp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// targets keep order of configuration
+	names := make([]string, 0)
+	for _, config := range configs {
+		names = append(names, config.TargetName)
+	}
+
+	if !slices.Equal(names, []string{"csharp", "ts"}) {
+		t.Errorf("got targets %v, want [csharp ts]", names)
+	}
+
+	_, err = getTargetConfigs(root, []string{"python"})
+	if err == nil || !strings.Contains(err.Error(), "available targets are csharp, go, ts") {
+		t.Errorf("got error %v, want unknown target error", err)
+	}
+}
+
+func TestGetTargetConfigsInvalidTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []map[string]interface{}
+		want    string
+	}{
+		{
+			name:    "missing name",
+			targets: []map[string]interface{}{{"outputfolder": "./cs"}},
+			want:    "target 0 has no name",
+		},
+		{
+			name:    "duplicate name",
+			targets: []map[string]interface{}{{"name": "cs", "outputfolder": "./a"}, {"name": "cs", "outputfolder": "./b"}},
+			want:    "target name cs is used multiple times",
+		},
+		{
+			name:    "shared key",
+			targets: []map[string]interface{}{{"name": "cs", "outputfolder": "./cs", "routinesfile": "./routines.json"}},
+			want:    "target cs: RoutinesFile cannot be set per target",
+		},
+		{
+			name:    "inherited output folder",
+			targets: []map[string]interface{}{{"name": "cs"}, {"name": "go", "outputfolder": "./go"}, {"name": "ts"}},
+			want:    "targets cs and ts have the same OutputFolder " + filepath.Clean("/project/output"),
+		},
+		{
+			name:    "same output folder written differently",
+			targets: []map[string]interface{}{{"name": "cs", "outputfolder": "./out"}, {"name": "go", "outputfolder": "out/../out/"}},
+			want:    "targets cs and go have the same OutputFolder " + filepath.Clean("/project/out"),
+		},
+	}
+
+	for _, test := range tests {
+		root := getTargetsTestRoot()
+		root.Targets = test.targets
+
+		// all targets are validated, even when only some are selected
+		_, err := getTargetConfigs(root, []string{"cs"})
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want %s", test.name, err, test.want)
+		}
+	}
+}
+
+func TestGetIntrospectionConfig(t *testing.T) {
+	configs := []*Config{
+		{TargetName: "cs", Generate: []SchemaConfig{{Schema: "public"}, {Schema: "admin"}}},
+		{TargetName: "go", Generate: []SchemaConfig{{Schema: "public"}, {Schema: "reports"}}},
+	}
+
+	introspectionConfig := GetIntrospectionConfig(configs)
+
+	// every schema is loaded once
+	if got := getSchemas(introspectionConfig); !slices.Equal(got, []string{"public", "admin", "reports"}) {
+		t.Errorf("got schemas %v, want [public admin reports]", got)
+	}
+
+	if len(configs[0].Generate) != 2 {
+		t.Errorf("Generate of first target was changed: %v", configs[0].Generate)
+	}
+
+	routines := []DbRoutine{
+		{RoutineSchema: "public", RoutineName: "get_users"},
+		{RoutineSchema: "admin", RoutineName: "delete_user"},
+		{RoutineSchema: "reports", RoutineName: "get_report"},
+	}
+
+	targetRoutines := GetTargetRoutines(routines, configs[1])
+	if len(targetRoutines) != 2 || targetRoutines[0].RoutineName != "get_users" || targetRoutines[1].RoutineName != "get_report" {
+		t.Errorf("got routines %v of go target, want get_users and get_report", targetRoutines)
+	}
+}
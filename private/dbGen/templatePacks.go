@@ -0,0 +1,200 @@
+package dbGen
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Template packs are sets of templates with outputs and mapping presets for common languages.
+// Built-in packs are embedded in executable and referenced with builtin: prefix, other packs are folders with pack.json
+
+//go:embed templatePacks
+var templatePacksFs embed.FS
+
+const (
+	templatePacksFolder  = "templatePacks"
+	templatePackFileName = "pack.json"
+	builtinPrefix        = "builtin:"
+)
+
+type TemplatePack struct {
+	Description       string         `json:"Description"`
+	MappingPresets    []string       `json:"MappingPresets"`
+	GeneratedFileCase string         `json:"GeneratedFileCase"`
+	Outputs           []OutputConfig `json:"Outputs"`
+}
+
+// GetTemplatePackNames returns names of all built-in template packs
+func GetTemplatePackNames() ([]string, error) {
+	entries, err := templatePacksFs.ReadDir(templatePacksFolder)
+	if err != nil {
+		return nil, fmt.Errorf("reading template packs: %s", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetTemplatePack returns built-in template pack
+func GetTemplatePack(name string) (*TemplatePack, error) {
+	content, err := readTemplateFile(builtinPrefix + path.Join(name, templatePackFileName))
+	if err != nil {
+		names, _ := GetTemplatePackNames()
+		return nil, fmt.Errorf("template pack '%s' doesn't exist, available packs: %s", name, strings.Join(names, ", "))
+	}
+
+	return parseTemplatePack(content)
+}
+
+// ExtractTemplatePack copies built-in template pack to folder, existing files are not overwritten unless force is set
+func ExtractTemplatePack(name string, folder string, force bool) ([]string, error) {
+	if _, err := GetTemplatePack(name); err != nil {
+		return nil, err
+	}
+
+	packFs, err := fs.Sub(templatePacksFs, path.Join(templatePacksFolder, name))
+	if err != nil {
+		return nil, err
+	}
+
+	packFiles := make([]string, 0)
+	err = fs.WalkDir(packFs, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			packFiles = append(packFiles, filePath)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("extracting template pack %s: %s", name, err)
+	}
+
+	// nothing is written when any file exists, so folder doesn't end up with mixed pack
+	files := make([]string, len(packFiles))
+	for i, filePath := range packFiles {
+		files[i] = filepath.Join(folder, filepath.FromSlash(filePath))
+
+		if !force && common2.PathExists(files[i]) {
+			return nil, fmt.Errorf("extracting template pack %s: file %s already exists, use force to overwrite it", name, files[i])
+		}
+	}
+
+	for i, filePath := range packFiles {
+		content, err := fs.ReadFile(packFs, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("extracting template pack %s: %s", name, err)
+		}
+
+		err = os.MkdirAll(filepath.Dir(files[i]), 0777)
+		if err != nil {
+			return nil, fmt.Errorf("extracting template pack %s: %s", name, err)
+		}
+
+		err = os.WriteFile(files[i], content, 0666)
+		if err != nil {
+			return nil, fmt.Errorf("extracting template pack %s: %s", name, err)
+		}
+	}
+
+	return files, nil
+}
+
+// applyTemplatePack fills missing settings from template pack and returns its outputs with resolved template paths
+func applyTemplatePack(config *Config) ([]OutputConfig, error) {
+	if config.TemplatePack == "" {
+		return nil, validateTemplateOverrides(config, nil)
+	}
+
+	packFolder := config.TemplatePack
+	if !isBuiltinPath(packFolder) {
+		packFolder = joinIfRelative(config.PathBase, packFolder)
+	}
+
+	content, err := readTemplateFile(joinTemplatePath(packFolder, templatePackFileName))
+	if err != nil {
+		return nil, fmt.Errorf("loading template pack %s: %s", config.TemplatePack, err)
+	}
+
+	pack, err := parseTemplatePack(content)
+	if err != nil {
+		return nil, fmt.Errorf("loading template pack %s: %s", config.TemplatePack, err)
+	}
+
+	if len(config.MappingPresets) == 0 {
+		config.MappingPresets = pack.MappingPresets
+	}
+
+	if config.GeneratedFileCase == "" {
+		config.GeneratedFileCase = pack.GeneratedFileCase
+	}
+
+	packTemplates := make([]string, len(pack.Outputs))
+	outputs := make([]OutputConfig, len(pack.Outputs))
+	for i, output := range pack.Outputs {
+		packTemplates[i] = path.Clean(filepath.ToSlash(output.Template))
+		output.Override = getTemplateOverride(config, packTemplates[i])
+		output.Template = joinTemplatePath(packFolder, output.Template)
+
+		// output case follows project settings, so pack can be used with any case
+		if output.FileCase == "" {
+			output.FileCase = strings.ToLower(config.GeneratedFileCase)
+		}
+
+		outputs[i] = output
+	}
+
+	err = validateTemplateOverrides(config, packTemplates)
+	if err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+func parseTemplatePack(content []byte) (*TemplatePack, error) {
+	pack := new(TemplatePack)
+
+	err := json.Unmarshal(content, pack)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %s", templatePackFileName, err)
+	}
+
+	return pack, nil
+}
+
+func isBuiltinPath(templatePath string) bool {
+	return strings.HasPrefix(templatePath, builtinPrefix)
+}
+
+// joinTemplatePath joins path relative to template folder, folder can be built-in
+func joinTemplatePath(folder string, templatePath string) string {
+	if isBuiltinPath(folder) {
+		return builtinPrefix + path.Join(strings.TrimPrefix(folder, builtinPrefix), filepath.ToSlash(templatePath))
+	}
+
+	return joinIfRelative(folder, templatePath)
+}
+
+// readTemplateFile reads template from disk or from built-in template packs
+func readTemplateFile(templatePath string) ([]byte, error) {
+	if isBuiltinPath(templatePath) {
+		return templatePacksFs.ReadFile(path.Join(templatePacksFolder, strings.TrimPrefix(templatePath, builtinPrefix)))
+	}
+
+	return os.ReadFile(templatePath)
+}
@@ -0,0 +1,227 @@
+package dbGen
+
+import (
+	"bytes"
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"github.com/keenmate/db-gen/private/version"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Template tests generate files from routines files of fixtures and compare them with expected (golden) files,
+// so template packs can be tested without database. Fixture is a folder:
+//
+//	<fixtures>/<name>/routines.json   routines saved by routines command
+//	<fixtures>/<name>/expected/...    expected files, relative to output folder (expected/<target>/... with targets)
+
+const (
+	fixtureRoutinesFile   = "routines.json"
+	fixtureExpectedFolder = "expected"
+)
+
+// build information is fixed, so expected files don't change with every db-gen release
+var templateTestBuildInfo = version.BuildInformation{
+	Builder:    "db-gen",
+	Version:    "TEST",
+	CommitHash: "",
+}
+
+type TemplateTestResult struct {
+	Fixture string
+	Diffs   []string // unified diffs of missing, unexpected and different files
+	Updated []string // expected files written or deleted by update
+}
+
+func (r *TemplateTestResult) Passed() bool {
+	return len(r.Diffs) == 0
+}
+
+// RunTemplateTests runs template tests of all fixtures in folder, update rewrites expected files with generated ones
+func RunTemplateTests(config *Config, fixturesFolder string, update bool) ([]TemplateTestResult, error) {
+	fixtures, err := getFixtures(fixturesFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %s, fixture is folder with %s", fixturesFolder, fixtureRoutinesFile)
+	}
+
+	results := make([]TemplateTestResult, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		result, err := runTemplateTest(config, fixturesFolder, fixture, update)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %s", fixture, err)
+		}
+
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// getFixtures returns sorted names of subfolders containing routines file
+func getFixtures(fixturesFolder string) ([]string, error) {
+	entries, err := os.ReadDir(fixturesFolder)
+	if err != nil {
+		return nil, fmt.Errorf("reading fixtures: %s", err)
+	}
+
+	fixtures := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() && common2.PathExists(filepath.Join(fixturesFolder, entry.Name(), fixtureRoutinesFile)) {
+			fixtures = append(fixtures, entry.Name())
+		}
+	}
+
+	sort.Strings(fixtures)
+
+	return fixtures, nil
+}
+
+func runTemplateTest(config *Config, fixturesFolder string, fixture string, update bool) (*TemplateTestResult, error) {
+	fixtureFolder := filepath.Join(fixturesFolder, fixture)
+
+	fixtureConfig := *config
+	fixtureConfig.UseRoutinesFile = true
+	fixtureConfig.RoutinesFile = filepath.Join(fixtureFolder, fixtureRoutinesFile)
+
+	files, err := generateFixture(&fixtureConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedFolder := filepath.Join(fixtureFolder, fixtureExpectedFolder, config.TargetName)
+	expectedFiles, err := readExpectedFiles(expectedFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TemplateTestResult{
+		Fixture: path.Join(fixture, config.TargetName),
+		Diffs:   make([]string, 0),
+		Updated: make([]string, 0),
+	}
+
+	for _, file := range files {
+		relPath := filepath.ToSlash(file.path)
+		expected, exists := expectedFiles[relPath]
+		delete(expectedFiles, relPath)
+
+		if exists && bytes.Equal(expected, file.content) {
+			continue
+		}
+
+		expectedName := path.Join(fixtureExpectedFolder, relPath)
+		if !exists {
+			expectedName = "/dev/null"
+		}
+
+		result.Diffs = append(result.Diffs, common2.UnifiedDiff(expectedName, path.Join("generated", relPath), string(expected), string(file.content)))
+
+		if update {
+			err = writeExpectedFile(filepath.Join(expectedFolder, file.path), file.content)
+			if err != nil {
+				return nil, err
+			}
+			result.Updated = append(result.Updated, relPath)
+		}
+	}
+
+	// remaining expected files are not generated anymore
+	staleFiles := make([]string, 0, len(expectedFiles))
+	for relPath := range expectedFiles {
+		staleFiles = append(staleFiles, relPath)
+	}
+	sort.Strings(staleFiles)
+
+	for _, relPath := range staleFiles {
+		result.Diffs = append(result.Diffs, common2.UnifiedDiff(path.Join(fixtureExpectedFolder, relPath), "/dev/null", string(expectedFiles[relPath]), ""))
+
+		if update {
+			err = os.Remove(filepath.Join(expectedFolder, filepath.FromSlash(relPath)))
+			if err != nil {
+				return nil, fmt.Errorf("removing expected file: %s", err)
+			}
+			result.Updated = append(result.Updated, relPath)
+		}
+	}
+
+	if update {
+		// updated fixture passes
+		result.Diffs = result.Diffs[:0]
+	}
+
+	return result, nil
+}
+
+// generateFixture runs the same pipeline as generate, but files are only rendered
+func generateFixture(config *Config) ([]generatedFile, error) {
+	dbRoutines, err := GetRoutines(config)
+	if err != nil {
+		return nil, err
+	}
+
+	routines := GetTargetRoutines(dbRoutines, config)
+	err = PreprocessRoutines(&routines, config)
+	if err != nil {
+		return nil, fmt.Errorf("preprocessing: %s", err)
+	}
+
+	processedRoutines, err := Process(routines, config)
+	if err != nil {
+		return nil, fmt.Errorf("processing: %s", err)
+	}
+
+	return renderOutputs(processedRoutines, &templateTestBuildInfo, config)
+}
+
+// readExpectedFiles returns content of expected files by slash separated path relative to folder
+func readExpectedFiles(folder string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if !common2.PathExists(folder) {
+		return files, nil
+	}
+
+	err := filepath.WalkDir(folder, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		relPath, err := filepath.Rel(folder, filePath)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(relPath)] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading expected files: %s", err)
+	}
+
+	return files, nil
+}
+
+func writeExpectedFile(filePath string, content []byte) error {
+	err := os.MkdirAll(filepath.Dir(filePath), 0777)
+	if err != nil {
+		return fmt.Errorf("creating expected folder: %s", err)
+	}
+
+	err = os.WriteFile(filePath, content, 0666)
+	if err != nil {
+		return fmt.Errorf("writing expected file: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package dbGen
+
+import (
+	"bytes"
+	"fmt"
+	common2 "github.com/keenmate/db-gen/private/helpers"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Generated files are applied to output folder as transaction. Changed files are written to staging folder first,
+// then deleted and replaced files are moved to backup folder and staged files are moved to their place.
+// When anything fails, moved files are returned back, so output folder is left as it was before generation.
+// Both folders are created inside output folder only when needed, so files are only renamed within one file system
+
+const (
+	stagingFolderPrefix = ".db-gen-staging-"
+	backupFolderPrefix  = ".db-gen-backup-"
+)
+
+type outputTransaction struct {
+	outputFolder  string
+	stagingFolder string   // created by first staged file
+	backupFolder  string   // created by first moved file
+	staged        []string // changed files relative to output folder
+	deleted       []string
+	steps         []*transactionStep // applied steps, undone in reverse order
+	keepBackup    bool               // rollback was not complete, backup is the only copy of some files
+	// os.Rename, tests replace it to simulate failures
+	rename func(oldPath string, newPath string) error
+}
+
+type transactionStep struct {
+	path     string // relative to output folder
+	backedUp bool   // original file was moved to backup folder
+	replaced bool   // staged file was moved to its place
+}
+
+func isTransactionFolder(name string) bool {
+	return strings.HasPrefix(name, stagingFolderPrefix) || strings.HasPrefix(name, backupFolderPrefix)
+}
+
+func newOutputTransaction(outputFolder string) (*outputTransaction, error) {
+	err := os.MkdirAll(outputFolder, 0777)
+	if err != nil {
+		return nil, fmt.Errorf("creating output folder: %s", err)
+	}
+
+	warnAboutLeftoverFolders(outputFolder)
+
+	return &outputTransaction{
+		outputFolder: outputFolder,
+		rename:       os.Rename,
+	}, nil
+}
+
+// warnAboutLeftoverFolders reports folders of interrupted generation, they are never deleted automatically,
+// as backup folder can be the only copy of original files
+func warnAboutLeftoverFolders(outputFolder string) {
+	entries, err := os.ReadDir(outputFolder)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !isTransactionFolder(entry.Name()) {
+			continue
+		}
+
+		folder := filepath.Join(outputFolder, entry.Name())
+		if strings.HasPrefix(entry.Name(), backupFolderPrefix) {
+			common2.LogWarn("Backup folder %s was left by interrupted generation, restore files from it if needed and delete it", folder)
+			continue
+		}
+
+		common2.LogWarn("Staging folder %s was left by interrupted generation, it can be deleted", folder)
+	}
+}
+
+// getTransactionFolder creates staging or backup folder on first use
+func (t *outputTransaction) getTransactionFolder(folder *string, prefix string) (string, error) {
+	if *folder != "" {
+		return *folder, nil
+	}
+
+	created, err := os.MkdirTemp(t.outputFolder, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	*folder = created
+	return created, nil
+}
+
+// stage writes file to staging folder when its content differs from output folder, returns if it was changed
+func (t *outputTransaction) stage(file generatedFile) (bool, error) {
+	targetPath := filepath.Join(t.outputFolder, file.path)
+
+	existing, err := os.ReadFile(targetPath)
+	if err == nil && bytes.Equal(existing, file.content) {
+		return false, nil
+	}
+
+	stagingFolder, err := t.getTransactionFolder(&t.stagingFolder, stagingFolderPrefix)
+	if err != nil {
+		return false, fmt.Errorf("creating staging folder: %s", err)
+	}
+
+	stagedPath := filepath.Join(stagingFolder, file.path)
+
+	err = os.MkdirAll(filepath.Dir(stagedPath), 0777)
+	if err != nil {
+		return false, fmt.Errorf("creating staging folder: %s", err)
+	}
+
+	// new file is created with umask applied, same as os.WriteFile did before
+	err = os.WriteFile(stagedPath, file.content, 0666)
+	if err != nil {
+		return false, err
+	}
+
+	// replaced file keeps its permissions
+	if info, err := os.Stat(targetPath); err == nil && info.Mode().IsRegular() {
+		err = os.Chmod(stagedPath, info.Mode().Perm())
+		if err != nil {
+			return false, err
+		}
+	}
+
+	t.staged = append(t.staged, file.path)
+	return true, nil
+}
+
+// delete marks file of output folder to be deleted by commit
+func (t *outputTransaction) delete(relPath string) {
+	t.deleted = append(t.deleted, filepath.FromSlash(relPath))
+}
+
+// commit deletes files and moves staged files to output folder, on error all changes are rolled back.
+// Files are deleted first, so generated file can replace deleted folder and the other way round
+func (t *outputTransaction) commit() error {
+	for _, relPath := range t.deleted {
+		if !common2.PathExists(filepath.Join(t.outputFolder, relPath)) {
+			continue
+		}
+
+		err := t.apply(relPath, false)
+		if err != nil {
+			t.rollback()
+			return fmt.Errorf("deleting %s: %s", relPath, err)
+		}
+
+		removeEmptyFolders(t.outputFolder, filepath.Dir(filepath.Join(t.outputFolder, relPath)))
+	}
+
+	for _, relPath := range t.staged {
+		err := t.apply(relPath, true)
+		if err != nil {
+			t.rollback()
+			return fmt.Errorf("replacing %s: %s", relPath, err)
+		}
+	}
+
+	return nil
+}
+
+// apply moves existing file to backup and staged file (if replace is set) to its place
+func (t *outputTransaction) apply(relPath string, replace bool) error {
+	step := &transactionStep{path: relPath}
+	t.steps = append(t.steps, step)
+
+	targetPath := filepath.Join(t.outputFolder, relPath)
+
+	if common2.PathExists(targetPath) {
+		backupFolder, err := t.getTransactionFolder(&t.backupFolder, backupFolderPrefix)
+		if err != nil {
+			return err
+		}
+
+		backupPath := filepath.Join(backupFolder, relPath)
+
+		err = os.MkdirAll(filepath.Dir(backupPath), 0777)
+		if err != nil {
+			return err
+		}
+
+		err = t.rename(targetPath, backupPath)
+		if err != nil {
+			return err
+		}
+		step.backedUp = true
+	}
+
+	if !replace {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(targetPath), 0777)
+	if err != nil {
+		return err
+	}
+
+	err = t.rename(filepath.Join(t.stagingFolder, relPath), targetPath)
+	if err != nil {
+		return err
+	}
+	step.replaced = true
+
+	return nil
+}
+
+// rollback returns files from backup folder, errors are only logged, so as many files as possible are restored
+func (t *outputTransaction) rollback() {
+	for i := len(t.steps) - 1; i >= 0; i-- {
+		step := t.steps[i]
+		targetPath := filepath.Join(t.outputFolder, step.path)
+
+		if step.replaced {
+			if err := os.Remove(targetPath); err != nil {
+				common2.LogWarn("Rollback of %s failed: %s", step.path, err)
+				t.keepBackup = t.keepBackup || step.backedUp
+				continue
+			}
+		}
+
+		if step.backedUp {
+			// folder could be removed when it became empty after delete
+			_ = os.MkdirAll(filepath.Dir(targetPath), 0777)
+
+			if err := t.rename(filepath.Join(t.backupFolder, step.path), targetPath); err != nil {
+				common2.LogWarn("Rollback of %s failed, original file is in %s: %s", step.path, t.backupFolder, err)
+				t.keepBackup = true
+				continue
+			}
+		}
+
+		// folders created for new files
+		removeEmptyFolders(t.outputFolder, filepath.Dir(targetPath))
+	}
+
+	t.steps = nil
+}
+
+// close removes staging and backup folders, backup is kept when rollback was not complete
+func (t *outputTransaction) close() {
+	if t.stagingFolder != "" {
+		_ = os.RemoveAll(t.stagingFolder)
+	}
+
+	if t.backupFolder != "" && !t.keepBackup {
+		_ = os.RemoveAll(t.backupFolder)
+	}
+}
+
+// removeEmptyFolders removes folder and its parents up to output folder while they are empty
+func removeEmptyFolders(outputFolder string, folder string) {
+	for {
+		relPath, err := filepath.Rel(outputFolder, folder)
+		if err != nil || relPath == "." || !filepath.IsLocal(relPath) {
+			return
+		}
+
+		// removing folder fails when it is not empty
+		if os.Remove(folder) != nil {
+			return
+		}
+
+		folder = filepath.Dir(folder)
+	}
+}
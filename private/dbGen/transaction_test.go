@@ -0,0 +1,216 @@
+package dbGen
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFolder returns content of all files in folder by slash separated relative path
+func readFolder(t *testing.T, folder string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	err := filepath.WalkDir(folder, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		relPath, err := filepath.Rel(folder, filePath)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(relPath)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return files
+}
+
+func writeFolder(t *testing.T, folder string, files map[string]string) {
+	t.Helper()
+
+	for relPath, content := range files {
+		filePath := filepath.Join(folder, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filePath, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func assertNoTransactionFolders(t *testing.T, folder string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if isTransactionFolder(entry.Name()) {
+			t.Errorf("transaction folder %s was left in output folder", entry.Name())
+		}
+	}
+}
+
+// prepareTransaction stages changes of the test output folder: modified, new (in new folder), deleted and unchanged file,
+// file replaced by folder and folder replaced by file
+func prepareTransaction(t *testing.T, outputFolder string) *outputTransaction {
+	t.Helper()
+
+	transaction, err := newOutputTransaction(outputFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated := []generatedFile{
+		{path: "modified.cs", content: []byte("modified new\n")},
+		{path: "unchanged.cs", content: []byte("unchanged\n")},
+		{path: filepath.Join("new", "created.cs"), content: []byte("created\n")},
+		{path: filepath.Join("file", "nested.cs"), content: []byte("nested\n")},
+		{path: "folder", content: []byte("folder is file now\n")},
+	}
+
+	for _, file := range generated {
+		if _, err := transaction.stage(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, relPath := range []string{"deleted.cs", "file", "folder/inner.cs"} {
+		transaction.delete(relPath)
+	}
+
+	return transaction
+}
+
+var originalFiles = map[string]string{
+	"modified.cs":     "modified old\n",
+	"unchanged.cs":    "unchanged\n",
+	"deleted.cs":      "deleted\n",
+	"file":            "file is folder now\n",
+	"folder/inner.cs": "inner\n",
+	"handwritten.txt": "not generated\n",
+}
+
+func TestOutputTransactionCommit(t *testing.T) {
+	outputFolder := t.TempDir()
+	writeFolder(t, outputFolder, originalFiles)
+
+	transaction := prepareTransaction(t, outputFolder)
+
+	err := transaction.commit()
+	transaction.close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"modified.cs":     "modified new\n",
+		"unchanged.cs":    "unchanged\n",
+		"new/created.cs":  "created\n",
+		"file/nested.cs":  "nested\n",
+		"folder":          "folder is file now\n",
+		"handwritten.txt": "not generated\n",
+	}
+
+	if got := readFolder(t, outputFolder); !maps.Equal(got, want) {
+		t.Errorf("output folder after commit:\n%v\nwant:\n%v", got, want)
+	}
+
+	assertNoTransactionFolders(t, outputFolder)
+}
+
+func TestOutputTransactionRollback(t *testing.T) {
+	renameCount := 0
+	{
+		outputFolder := t.TempDir()
+		writeFolder(t, outputFolder, originalFiles)
+
+		transaction := prepareTransaction(t, outputFolder)
+		transaction.rename = func(oldPath string, newPath string) error {
+			renameCount++
+			return os.Rename(oldPath, newPath)
+		}
+
+		if err := transaction.commit(); err != nil {
+			t.Fatal(err)
+		}
+		transaction.close()
+	}
+
+	// every rename of commit fails once
+	for failingRename := 1; failingRename <= renameCount; failingRename++ {
+		outputFolder := t.TempDir()
+		writeFolder(t, outputFolder, originalFiles)
+
+		transaction := prepareTransaction(t, outputFolder)
+
+		renames := 0
+		transaction.rename = func(oldPath string, newPath string) error {
+			renames++
+			if renames == failingRename {
+				return errors.New("simulated failure")
+			}
+
+			return os.Rename(oldPath, newPath)
+		}
+
+		err := transaction.commit()
+		transaction.close()
+
+		if err == nil {
+			t.Fatalf("rename %d: commit didn't fail", failingRename)
+		}
+
+		if got := readFolder(t, outputFolder); !maps.Equal(got, originalFiles) {
+			t.Errorf("rename %d: output folder was not restored:\n%v\nwant:\n%v", failingRename, got, originalFiles)
+		}
+
+		assertNoTransactionFolders(t, outputFolder)
+	}
+}
+
+func TestOutputTransactionWithoutChanges(t *testing.T) {
+	outputFolder := t.TempDir()
+	writeFolder(t, outputFolder, map[string]string{"unchanged.cs": "unchanged\n"})
+
+	transaction, err := newOutputTransaction(outputFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transaction.close()
+
+	changed, err := transaction.stage(generatedFile{path: "unchanged.cs", content: []byte("unchanged\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changed {
+		t.Error("unchanged file was staged")
+	}
+
+	// folders are created only when something is written
+	assertNoTransactionFolders(t, outputFolder)
+
+	if err := transaction.commit(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -10,8 +10,17 @@ type Property struct {
 	PropertyType   string
 	Position       int
 	MapperFunction string
-	Nullable       bool // This can be unreliable
-	Optional       bool // only used in Params
+	TypeParameter  string // set when type is polymorphic (anyelement, anyarray...)
+	JsonModel      string // name of model generated from json schema
+	IsRange        bool   // true for both ranges and multiranges
+	IsMultirange   bool
+	RangeSubtype   string // database type of range elements
+	Nullable       bool   // This can be unreliable
+	Optional       bool   // only used in Params
+	Tags           []string
+	Extra          map[string]interface{}
+
+	fallbackTypes []string // used for unmapped type report
 }
 
 type Routine struct {
@@ -23,24 +32,69 @@ type Routine struct {
 	DbFunctionName     string
 	HasReturn          bool
 	IsProcedure        bool
+	TypeParameters     []string // generic type parameters of polymorphic routine
 	Parameters         []Property
 	ReturnProperties   []Property
+	JsonModels         []JsonModel // models generated from json schemas of parameters and columns
+	Enums              []Enum      // enums used by parameters and columns
+	CustomType         *CustomType // composite type returned by routine
+	Sql                RoutineSql  // call statements
+	Tags               []string    // tags from Functions entry and matching pattern entries
+	Extra              map[string]interface{}
 }
 
 type DbContextData struct {
-	Config    *Config
-	Functions []Routine
-	BuildInfo *version.BuildInformation
+	Config      *Config
+	Functions   []Routine
+	JsonModels  []JsonModel            // distinct models of all functions
+	Enums       []Enum                 // distinct enums of all functions
+	CustomTypes []CustomType           // distinct custom types of all functions
+	Vars        map[string]interface{} // user defined variables
+	BuildInfo   *version.BuildInformation
 }
 
 type ProcessorTemplateData struct {
 	Config    *Config
 	Routine   Routine
+	Vars      map[string]interface{}
+	BuildInfo *version.BuildInformation
+}
+
+type SchemaTemplateData struct {
+	Config      *Config
+	Schema      string
+	Functions   []Routine
+	JsonModels  []JsonModel
+	Enums       []Enum
+	CustomTypes []CustomType
+	Vars        map[string]interface{}
+	BuildInfo   *version.BuildInformation
+}
+
+type EnumTemplateData struct {
+	Config    *Config
+	Enum      Enum
+	Vars      map[string]interface{}
+	BuildInfo *version.BuildInformation
+}
+
+type CustomTypeTemplateData struct {
+	Config     *Config
+	CustomType CustomType
+	Vars       map[string]interface{}
+	BuildInfo  *version.BuildInformation
+}
+
+type JsonModelTemplateData struct {
+	Config    *Config
+	Model     JsonModel
+	Vars      map[string]interface{}
 	BuildInfo *version.BuildInformation
 }
 
 type ModelTemplateData struct {
 	Config    *Config
 	Routine   Routine
+	Vars      map[string]interface{}
 	BuildInfo *version.BuildInformation
 }
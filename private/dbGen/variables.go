@@ -0,0 +1,41 @@
+package dbGen
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"maps"
+	"strings"
+)
+
+// Variables are project specific values (namespace, package name, license header...) available in templates as .Vars.
+// Variables from command line (--var key=value) override configuration of all targets
+
+const VariablesFlagKey = "var"
+
+// restoreVariables replaces variables with raw values, because viper lowercases keys
+func restoreVariables(config *Config, rawPath ...interface{}) {
+	rawVariables, exists := getRawConfigValue(append(rawPath, "Variables")...)
+	if variables, isMap := rawVariables.(map[string]interface{}); exists && isMap {
+		config.Variables = variables
+	}
+}
+
+func applyCommandLineVariables(config *Config) error {
+	// variables can be shared by multiple targets, so they are copied
+	variables := maps.Clone(config.Variables)
+	if variables == nil {
+		variables = make(map[string]interface{})
+	}
+
+	for _, variable := range viper.GetStringSlice(VariablesFlagKey) {
+		key, value, found := strings.Cut(variable, "=")
+		if !found || key == "" {
+			return fmt.Errorf("invalid variable '%s', expected key=value", variable)
+		}
+
+		variables[key] = value
+	}
+
+	config.Variables = variables
+	return nil
+}
@@ -0,0 +1,244 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lines of unchanged text printed around changes
+const diffContextLines = 3
+
+// files with more changed lines are diffed as replaced, shortest edit script would take too much memory
+const maxDiffEdits = 1000
+
+const (
+	diffEqual  = ' '
+	diffDelete = '-'
+	diffInsert = '+'
+)
+
+type diffOperation struct {
+	kind byte
+	line string
+	from int // position in original lines before this operation
+	to   int // position in new lines before this operation
+}
+
+// UnifiedDiff returns unified diff of two texts, empty string if they are the same
+func UnifiedDiff(fromName string, toName string, from string, to string) string {
+	if from == to {
+		return ""
+	}
+
+	operations := diffLines(splitLines(from), splitLines(to))
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	for _, hunk := range getDiffHunks(operations) {
+		writeDiffHunk(&out, operations[hunk[0]:hunk[1]])
+	}
+
+	return out.String()
+}
+
+// splitLines splits text to lines keeping line endings, so missing newline at the end is visible in diff
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines finds the shortest edit script using Myers algorithm, lines that are the same at the beginning
+// and at the end are left out of the search
+func diffLines(from []string, to []string) []diffOperation {
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	operations := make([]diffOperation, 0, len(from)+len(to))
+	for i := 0; i < prefix; i++ {
+		operations = append(operations, diffOperation{kind: diffEqual, line: from[i], from: i, to: i})
+	}
+
+	changedFrom, changedTo := from[prefix:len(from)-suffix], to[prefix:len(to)-suffix]
+	changed, found := myersDiff(changedFrom, changedTo)
+	if !found {
+		changed = replaceLines(changedFrom, changedTo)
+	}
+
+	for _, operation := range changed {
+		operation.from += prefix
+		operation.to += prefix
+		operations = append(operations, operation)
+	}
+
+	for i := 0; i < suffix; i++ {
+		x, y := len(from)-suffix+i, len(to)-suffix+i
+		operations = append(operations, diffOperation{kind: diffEqual, line: from[x], from: x, to: y})
+	}
+
+	return operations
+}
+
+// myersDiff returns the shortest edit script, false when it is longer than maxDiffEdits.
+// Every step saves only diagonals reachable in it, so memory is quadratic in number of edits, not in number of lines
+func myersDiff(from []string, to []string) ([]diffOperation, bool) {
+	n, m := len(from), len(to)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] holds v of diagonals -d-1..d+1 before step d
+	trace := make([][]int, 0)
+
+search:
+	for d := 0; d <= n+m; d++ {
+		if d > maxDiffEdits {
+			return nil, false
+		}
+
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && from[x] == to[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk back through saved states to recover the edit script
+	operations := make([]diffOperation, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		saved := trace[d]
+		// diagonal k is at saved[k+d+1]
+		vAt := func(k int) int {
+			return saved[k+d+1]
+		}
+		k := x - y
+
+		var previousK int
+		if k == -d || (k != d && vAt(k-1) < vAt(k+1)) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+		previousX := vAt(previousK)
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			x--
+			y--
+			operations = append(operations, diffOperation{kind: diffEqual, line: from[x], from: x, to: y})
+		}
+
+		if d > 0 {
+			if x == previousX {
+				y--
+				operations = append(operations, diffOperation{kind: diffInsert, line: to[y], from: x, to: y})
+			} else {
+				x--
+				operations = append(operations, diffOperation{kind: diffDelete, line: from[x], from: x, to: y})
+			}
+		}
+
+		x, y = previousX, previousY
+	}
+
+	for i, j := 0, len(operations)-1; i < j; i, j = i+1, j-1 {
+		operations[i], operations[j] = operations[j], operations[i]
+	}
+
+	return operations, true
+}
+
+// replaceLines deletes all original lines and inserts all new ones
+func replaceLines(from []string, to []string) []diffOperation {
+	operations := make([]diffOperation, 0, len(from)+len(to))
+	for x, line := range from {
+		operations = append(operations, diffOperation{kind: diffDelete, line: line, from: x, to: 0})
+	}
+
+	for y, line := range to {
+		operations = append(operations, diffOperation{kind: diffInsert, line: line, from: len(from), to: y})
+	}
+
+	return operations
+}
+
+// getDiffHunks returns ranges of operations with changes and their context, close changes share one hunk
+func getDiffHunks(operations []diffOperation) [][2]int {
+	hunks := make([][2]int, 0)
+
+	for i, operation := range operations {
+		if operation.kind == diffEqual {
+			continue
+		}
+
+		start := max(i-diffContextLines, 0)
+		end := min(i+diffContextLines+1, len(operations))
+
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1][1] {
+			hunks[len(hunks)-1][1] = end
+			continue
+		}
+
+		hunks = append(hunks, [2]int{start, end})
+	}
+
+	return hunks
+}
+
+func writeDiffHunk(out *strings.Builder, operations []diffOperation) {
+	fromCount, toCount := 0, 0
+	for _, operation := range operations {
+		if operation.kind != diffInsert {
+			fromCount++
+		}
+		if operation.kind != diffDelete {
+			toCount++
+		}
+	}
+
+	// empty range starts at line before it
+	fromStart, toStart := operations[0].from, operations[0].to
+	if fromCount > 0 {
+		fromStart++
+	}
+	if toCount > 0 {
+		toStart++
+	}
+
+	out.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount))
+
+	for _, operation := range operations {
+		out.WriteByte(operation.kind)
+		out.WriteString(operation.line)
+
+		if !strings.HasSuffix(operation.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
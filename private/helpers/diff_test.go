@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "same",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+			want: "",
+		},
+		{
+			name: "insert",
+			from: "a\nb\nc\n",
+			to:   "a\nb\nx\nc\n",
+			want: "--- from\n+++ to\n" +
+				"@@ -1,3 +1,4 @@\n" +
+				" a\n b\n+x\n c\n",
+		},
+		{
+			name: "delete",
+			from: "a\nb\nc\n",
+			to:   "a\nc\n",
+			want: "--- from\n+++ to\n" +
+				"@@ -1,3 +1,2 @@\n" +
+				" a\n-b\n c\n",
+		},
+		{
+			name: "new file",
+			from: "",
+			to:   "a\nb\n",
+			want: "--- from\n+++ to\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+a\n+b\n",
+		},
+		{
+			name: "deleted file",
+			from: "a\nb\n",
+			to:   "",
+			want: "--- from\n+++ to\n" +
+				"@@ -1,2 +0,0 @@\n" +
+				"-a\n-b\n",
+		},
+		{
+			name: "missing final newline",
+			from: "a\nb",
+			to:   "a\nb\n",
+			want: "--- from\n+++ to\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				" a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			to:   "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			want: "--- from\n+++ to\n" +
+				"@@ -1,4 +1,4 @@\n" +
+				"-1\n+x\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n" +
+				" 9\n 10\n 11\n-12\n+y\n",
+		},
+		{
+			name: "merged hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			to:   "x\n2\n3\n4\n5\n6\n7\ny\n",
+			want: "--- from\n+++ to\n" +
+				"@@ -1,8 +1,8 @@\n" +
+				"-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n",
+		},
+	}
+
+	for _, test := range tests {
+		got := UnifiedDiff("from", "to", test.from, test.to)
+		if got != test.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUnifiedDiffTooManyEdits(t *testing.T) {
+	var from, to strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&from, "from %d\n", i)
+		fmt.Fprintf(&to, "to %d\n", i)
+	}
+
+	got := UnifiedDiff("from", "to", "same\n"+from.String(), "same\n"+to.String())
+
+	// replaced as a whole, common first line is kept as context
+	if !strings.HasPrefix(got, "--- from\n+++ to\n@@ -1,5001 +1,5001 @@\n same\n-from 0\n") {
+		t.Errorf("unexpected start of diff:\n%s", got[:min(len(got), 200)])
+	}
+
+	deleted, inserted := 0, 0
+	for _, line := range strings.Split(got, "\n")[2:] {
+		switch {
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		case strings.HasPrefix(line, "+"):
+			inserted++
+		}
+	}
+
+	if deleted != 5000 || inserted != 5000 {
+		t.Errorf("expected 5000 deleted and 5000 inserted lines, got %d and %d", deleted, inserted)
+	}
+}
@@ -59,6 +59,79 @@ func NewBoolFlag(key string, shorthand string, defaultValue bool, usage string)
 	}
 }
 
+type StringSliceFlag struct {
+	key          string
+	shorthand    string
+	defaultValue []string
+	usage        string
+}
+
+func (f *StringSliceFlag) DefineFlag(command *cobra.Command) {
+	command.Flags().StringSliceP(f.key, f.shorthand, f.defaultValue, f.usage)
+}
+
+func (f *StringSliceFlag) BindFlag(command *cobra.Command) {
+	_ = viper.BindPFlag(f.key, command.Flags().Lookup(f.key))
+}
+
+func NewStringSliceFlag(key string, shorthand string, defaultValue []string, usage string) *StringSliceFlag {
+	return &StringSliceFlag{
+		key:          key,
+		shorthand:    shorthand,
+		defaultValue: defaultValue,
+		usage:        usage,
+	}
+}
+
+type IntFlag struct {
+	key          string
+	shorthand    string
+	defaultValue int
+	usage        string
+}
+
+func (f *IntFlag) DefineFlag(command *cobra.Command) {
+	command.Flags().IntP(f.key, f.shorthand, f.defaultValue, f.usage)
+}
+
+func (f *IntFlag) BindFlag(command *cobra.Command) {
+	_ = viper.BindPFlag(f.key, command.Flags().Lookup(f.key))
+}
+
+func NewIntFlag(key string, shorthand string, defaultValue int, usage string) *IntFlag {
+	return &IntFlag{
+		key:          key,
+		shorthand:    shorthand,
+		defaultValue: defaultValue,
+		usage:        usage,
+	}
+}
+
+// StringArrayFlag is like StringSliceFlag, but values are not split by comma
+type StringArrayFlag struct {
+	key          string
+	shorthand    string
+	defaultValue []string
+	usage        string
+}
+
+func (f *StringArrayFlag) DefineFlag(command *cobra.Command) {
+	command.Flags().StringArrayP(f.key, f.shorthand, f.defaultValue, f.usage)
+}
+
+func (f *StringArrayFlag) BindFlag(command *cobra.Command) {
+	_ = viper.BindPFlag(f.key, command.Flags().Lookup(f.key))
+}
+
+func NewStringArrayFlag(key string, shorthand string, defaultValue []string, usage string) *StringArrayFlag {
+	return &StringArrayFlag{
+		key:          key,
+		shorthand:    shorthand,
+		defaultValue: defaultValue,
+		usage:        usage,
+	}
+}
+
 // BindFlags we nned to separate binding from declaration if we dont have unique name for each flag
 func BindFlags(command *cobra.Command, flags []FlagArgument) {
 	for _, flag := range flags {
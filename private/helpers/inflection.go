@@ -0,0 +1,189 @@
+package helpers
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Simple english inflection of identifiers, only the last word is changed, e.g. UserRole -> UserRoles, sales_person -> sales_people.
+// Changed rule changes generated names, so rules are covered by inflection_test.go.
+
+type inflectionRule struct {
+	suffix      string
+	replacement string
+}
+
+var irregularWords = [][2]string{
+	{"child", "children"},
+	{"mouse", "mice"},
+	{"goose", "geese"},
+	{"foot", "feet"},
+	{"tooth", "teeth"},
+	{"ox", "oxen"},
+	{"index", "indices"},
+	{"matrix", "matrices"},
+	{"vertex", "vertices"},
+	{"criterion", "criteria"},
+	{"analysis", "analyses"},
+	// singular words ending with s, other words ending with s are taken as plural
+	{"gas", "gases"},
+	{"alias", "aliases"},
+	{"bias", "biases"},
+	{"atlas", "atlases"},
+	{"canvas", "canvases"},
+	{"lens", "lenses"},
+}
+
+// irregular endings of compound words, e.g. Salesman -> Salesmen, salesperson -> salespeople
+var irregularSuffixes = [][2]string{
+	{"person", "people"},
+	{"man", "men"},
+}
+
+// words with irregular suffix that are inflected by rules
+var regularWords = []string{
+	"human", "german", "roman", "shaman", "talisman", "caiman", "ottoman", "specimen", "omen", "abdomen", "stamen",
+}
+
+var uncountableWords = []string{
+	"data", "information", "equipment", "series", "species", "news", "metadata", "sheep", "fish", "money", "rice",
+}
+
+var pluralRules = []inflectionRule{
+	{"fe", "ves"},
+	{"lf", "lves"},
+	{"sis", "ses"},
+	{"ay", "ays"},
+	{"ey", "eys"},
+	{"oy", "oys"},
+	{"uy", "uys"},
+	{"y", "ies"},
+	{"ss", "sses"},
+	{"us", "uses"},
+	{"ch", "ches"},
+	{"sh", "shes"},
+	{"x", "xes"},
+	{"z", "zes"},
+	{"s", "s"},
+	{"", "s"},
+}
+
+var singularRules = []inflectionRule{
+	{"ives", "ife"},
+	{"lves", "lf"},
+	{"vies", "vie"},
+	{"ies", "y"},
+	{"sses", "ss"},
+	{"uses", "us"},
+	{"ches", "ch"},
+	{"shes", "sh"},
+	{"xes", "x"},
+	{"zes", "z"},
+	{"ss", "ss"},
+	{"us", "us"},
+	{"is", "is"},
+	{"s", ""},
+}
+
+func Pluralize(s string) string {
+	return inflect(s, pluralRules, false)
+}
+
+func Singularize(s string) string {
+	return inflect(s, singularRules, true)
+}
+
+func inflect(s string, rules []inflectionRule, singular bool) string {
+	if s == "" {
+		return s
+	}
+
+	lower := strings.ToLower(s)
+	if s != lower && s == strings.ToUpper(s) {
+		return strings.ToUpper(inflect(lower, rules, singular))
+	}
+
+	for _, word := range uncountableWords {
+		if hasWordSuffix(s, lower, word) {
+			return s
+		}
+	}
+
+	if replaced, found := inflectIrregular(s, lower, irregularWords, singular, true); found {
+		return replaced
+	}
+
+	if !slices.ContainsFunc(regularWords, func(word string) bool { return hasWordSuffix(s, lower, word) }) {
+		if replaced, found := inflectIrregular(s, lower, irregularSuffixes, singular, false); found {
+			return replaced
+		}
+	}
+
+	for _, rule := range rules {
+		if strings.HasSuffix(lower, rule.suffix) {
+			start := len(s) - len(rule.suffix)
+			return s[:start] + matchCase(s[start:], rule.replacement)
+		}
+	}
+
+	return s
+}
+
+// inflectIrregular replaces irregular word (or any ending when wholeWord is not set), word already
+// in requested form is returned unchanged
+func inflectIrregular(s string, lower string, irregulars [][2]string, singular bool, wholeWord bool) (string, bool) {
+	hasSuffix := func(suffix string) bool {
+		if wholeWord {
+			return hasWordSuffix(s, lower, suffix)
+		}
+
+		return strings.HasSuffix(lower, suffix)
+	}
+
+	for _, irregular := range irregulars {
+		from, to := irregular[0], irregular[1]
+		if singular {
+			from, to = to, from
+		}
+
+		if hasSuffix(to) {
+			return s, true
+		}
+
+		if hasSuffix(from) {
+			start := len(s) - len(from)
+			return s[:start] + matchCase(s[start:], to), true
+		}
+	}
+
+	return "", false
+}
+
+// hasWordSuffix checks that s ends with whole word, word starts after separator, at the beginning or with upper case letter
+func hasWordSuffix(s string, lower string, word string) bool {
+	if !strings.HasSuffix(lower, word) {
+		return false
+	}
+
+	start := len(s) - len(word)
+	if start == 0 {
+		return true
+	}
+
+	previous := rune(s[start-1])
+	return previous == '_' || previous == '-' || previous == ' ' || unicode.IsUpper(rune(s[start]))
+}
+
+// matchCase capitalizes replacement when replaced text is capitalized
+func matchCase(original string, replacement string) string {
+	if original == "" || replacement == "" {
+		return replacement
+	}
+
+	if unicode.IsUpper(rune(original[0])) {
+		return strings.ToUpper(replacement[:1]) + replacement[1:]
+	}
+
+	return replacement
+}
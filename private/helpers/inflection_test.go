@@ -0,0 +1,103 @@
+package helpers
+
+import "testing"
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"", ""},
+		{"user", "users"},
+		{"UserRole", "UserRoles"},
+		{"user_role", "user_roles"},
+		{"USER", "USERS"},
+		{"users", "users"},
+		{"schemas", "schemas"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"movie", "movies"},
+		{"address", "addresses"},
+		{"status", "statuses"},
+		{"box", "boxes"},
+		{"branch", "branches"},
+		{"knife", "knives"},
+		{"shelf", "shelves"},
+		{"gas", "gases"},
+		{"Alias", "Aliases"},
+		{"gases", "gases"},
+		{"person", "people"},
+		{"salesperson", "salespeople"},
+		{"sales_person", "sales_people"},
+		{"people", "people"},
+		{"man", "men"},
+		{"Salesman", "Salesmen"},
+		{"SalesMan", "SalesMen"},
+		{"woman", "women"},
+		{"women", "women"},
+		{"human", "humans"},
+		{"German", "Germans"},
+		{"child", "children"},
+		{"ox", "oxen"},
+		{"index", "indices"},
+		{"analysis", "analyses"},
+		{"data", "data"},
+		{"user_metadata", "user_metadata"},
+	}
+
+	for _, test := range tests {
+		if got := Pluralize(test.word); got != test.want {
+			t.Errorf("Pluralize(%q) = %q, want %q", test.word, got, test.want)
+		}
+	}
+}
+
+func TestSingularize(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"", ""},
+		{"users", "user"},
+		{"UserRoles", "UserRole"},
+		{"USERS", "USER"},
+		{"user", "user"},
+		{"categories", "category"},
+		{"days", "day"},
+		{"movies", "movie"},
+		{"Movies", "Movie"},
+		{"addresses", "address"},
+		{"address", "address"},
+		{"statuses", "status"},
+		{"status", "status"},
+		{"boxes", "box"},
+		{"branches", "branch"},
+		{"knives", "knife"},
+		{"shelves", "shelf"},
+		{"cases", "case"},
+		{"databases", "database"},
+		{"gases", "gas"},
+		{"gas", "gas"},
+		{"aliases", "alias"},
+		{"people", "person"},
+		{"salespeople", "salesperson"},
+		{"person", "person"},
+		{"men", "man"},
+		{"Salesmen", "Salesman"},
+		{"Salesman", "Salesman"},
+		{"women", "woman"},
+		{"humans", "human"},
+		{"specimen", "specimen"},
+		{"children", "child"},
+		{"oxen", "ox"},
+		{"analyses", "analysis"},
+		{"analysis", "analysis"},
+		{"news", "news"},
+	}
+
+	for _, test := range tests {
+		if got := Singularize(test.word); got != test.want {
+			t.Errorf("Singularize(%q) = %q, want %q", test.word, got, test.want)
+		}
+	}
+}
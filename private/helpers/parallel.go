@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"runtime"
+	"sync"
+)
+
+// RunParallel runs job for indexes 0 to count-1 on at most jobs goroutines, jobs lower than 1 means number of CPUs.
+// Indexes are started in order and no new index is started after error, so all lower indexes are finished
+// and returned error is the one with the lowest index, the same as in sequential run
+func RunParallel(count int, jobs int, job func(index int) error) error {
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	jobs = min(jobs, count)
+
+	if jobs <= 1 {
+		for i := 0; i < count; i++ {
+			if err := job(i); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	errs := make([]error, count)
+	next := 0
+	failed := false
+	// guards next and failed, so index is never started after failure is recorded
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	nextIndex := func() (int, bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if failed || next >= count {
+			return 0, false
+		}
+
+		next++
+		return next - 1, true
+	}
+
+	for worker := 0; worker < jobs; worker++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i, ok := nextIndex(); ok; i, ok = nextIndex() {
+				if err := job(i); err != nil {
+					mutex.Lock()
+					errs[i] = err
+					failed = true
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package helpers
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunParallelRunsAllIndexes(t *testing.T) {
+	for _, test := range []struct{ count, jobs int }{
+		{0, 4},
+		{1, 4},
+		{3, 10},
+		{100, 1},
+		{100, 4},
+		{100, 0},
+	} {
+		var mutex sync.Mutex
+		started := make([]int, 0)
+
+		err := RunParallel(test.count, test.jobs, func(index int) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			started = append(started, index)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("count %d, jobs %d: %s", test.count, test.jobs, err)
+		}
+
+		slices.Sort(started)
+		for i, index := range started {
+			if i != index {
+				t.Fatalf("count %d, jobs %d: index %d is missing or was run more than once", test.count, test.jobs, i)
+			}
+		}
+
+		if len(started) != test.count {
+			t.Errorf("count %d, jobs %d: %d indexes were run", test.count, test.jobs, len(started))
+		}
+	}
+}
+
+func TestRunParallelReturnsLowestIndexError(t *testing.T) {
+	for _, jobs := range []int{1, 2, 8} {
+		err := RunParallel(100, jobs, func(index int) error {
+			if index%10 != 7 {
+				return nil
+			}
+
+			// higher indexes fail first
+			if index == 7 {
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			return fmt.Errorf("failed %d", index)
+		})
+
+		if err == nil || err.Error() != "failed 7" {
+			t.Errorf("jobs %d: got error %v, want failed 7", jobs, err)
+		}
+	}
+}
+
+func TestRunParallelStopsAfterError(t *testing.T) {
+	const jobs = 4
+
+	var mutex sync.Mutex
+	started := make([]int, 0)
+
+	err := RunParallel(1000, jobs, func(index int) error {
+		mutex.Lock()
+		started = append(started, index)
+		mutex.Unlock()
+
+		if index == 0 {
+			return fmt.Errorf("failed")
+		}
+
+		// running jobs are still running when the failure is recorded
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+
+	if err == nil {
+		t.Fatal("error was not returned")
+	}
+
+	// only indexes started before failure of the first one, one per worker
+	if len(started) > jobs {
+		t.Errorf("indexes %v were started, at most %d expected", started, jobs)
+	}
+}
@@ -17,6 +17,8 @@ create table example_table
 	ltree  ext.ltree
 );
 
+comment on column example_table.jsonb is '@schema ./schemas/example.schema.json';
+
 insert into example_table (number, string, json, jsonb, ltree)
 values (1, 'Hello world', json_build_object('key', 'value', 'number', 12),
 				json_build_object('key', 'value', 'number', 12)::jsonb, '1.2.3');
@@ -154,3 +156,38 @@ $$;
 
 select overloaded_function('franta');
 select overloaded_function(1);
+
+create or replace function coalesce_values(value anyelement, fallback_values anyarray) returns anyelement
+	language sql
+as
+$$
+select coalesce(value, fallback_values[1]);
+$$;
+
+create or replace function shift_period(period tstzrange, shift interval)
+	returns table
+					(
+						original tstzrange,
+						shifted  tstzrange
+					)
+	language sql
+as
+$$
+select period, tstzrange(lower(period) + shift, upper(period) + shift);
+$$;
+
+create type mood as enum ('happy', 'neutral', 'sad');
+
+create or replace function describe_mood(current_mood mood) returns mood
+	language sql
+as
+$$
+select current_mood;
+$$;
+
+create or replace function greet(name text, greeting text default 'Hello', punctuation text default '!') returns text
+	language sql
+as
+$$
+select greeting || ', ' || name || punctuation;
+$$;